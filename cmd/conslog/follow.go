@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// tail reads complete lines of a followed file.
+type tail struct {
+	f       *os.File
+	br      *bufio.Reader
+	offset  int64  // bytes read from the current file
+	partial []byte // incomplete last line
+	fn      func(line []byte) error
+}
+
+// open starts reading the file at name from its beginning.
+func (t *tail) open(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	t.f, t.offset, t.partial = f, 0, t.partial[:0]
+	if t.br == nil {
+		t.br = bufio.NewReader(f)
+	} else {
+		t.br.Reset(f)
+	}
+	return nil
+}
+
+// drain passes all complete lines up to the end of the file to fn.
+func (t *tail) drain() error {
+	for {
+		line, err := t.br.ReadBytes('\n')
+		t.offset += int64(len(line))
+		t.partial = append(t.partial, line...)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := t.fn(t.partial); err != nil {
+			return err
+		}
+		t.partial = t.partial[:0]
+	}
+}
+
+// followFile passes lines of the file at name to fn and then waits for
+// appended lines like tail -f, calling idle whenever the end is reached.
+// A file replaced by rotation is reopened from its beginning after the
+// rest of the old file was read, a truncated file is read again.
+// Returns when ctx is done.
+func followFile(ctx context.Context, name string, interval time.Duration, fn func([]byte) error, idle func() error) error {
+	t := &tail{fn: fn}
+	if err := t.open(name); err != nil {
+		return err
+	}
+	defer func() { t.f.Close() }()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := t.drain(); err != nil {
+			return err
+		}
+		if err := idle(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		fi, err := os.Stat(name)
+		if err != nil {
+			continue // rotated away, wait for the new file
+		}
+		cur, err := t.f.Stat()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case !os.SameFile(fi, cur):
+			if err := t.drain(); err != nil {
+				return err
+			}
+			if len(t.partial) > 0 {
+				if err := fn(t.partial); err != nil {
+					return err
+				}
+			}
+			t.f.Close()
+			if err := t.open(name); err != nil {
+				return err
+			}
+		case fi.Size() < t.offset:
+			if _, err := t.f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			t.br.Reset(t.f)
+			t.offset, t.partial = 0, t.partial[:0]
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestFollowFile verifies following appended lines across rotation and truncation.
+func TestFollowFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	write := func(flag int, s string) {
+		t.Helper()
+		f, err := os.OpenFile(path, flag|os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(os.O_TRUNC, "first\n")
+
+	var (
+		mu    sync.Mutex
+		lines []string
+	)
+	got := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lines...)
+	}
+	waitFor := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for len(got()) < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %d lines, got %q", n, got())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() {
+		done <- followFile(ctx, path, 10*time.Millisecond, func(line []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			lines = append(lines, strings.TrimSuffix(string(line), "\n"))
+			return nil
+		}, func() error { return nil })
+	}()
+
+	waitFor(1)
+	write(os.O_APPEND, "sec")
+	time.Sleep(30 * time.Millisecond)
+	write(os.O_APPEND, "ond\n")
+	waitFor(2)
+
+	// rotate: the rest of the old file is read before the new one
+	write(os.O_APPEND, "last of old\nunterminated")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	write(os.O_TRUNC, "new file\n")
+	waitFor(5)
+
+	// truncate in place, detected by the shrunken size
+	write(os.O_TRUNC, "cut\n")
+	waitFor(6)
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	want := []string{"first", "second", "last of old", "unterminated", "new file", "cut"}
+	if strings.Join(got(), ",") != strings.Join(want, ",") {
+		t.Errorf("expected %q, got %q", want, got())
+	}
+}
@@ -0,0 +1,178 @@
+// Command conslog views JSON and logfmt logs in the colorized console format.
+//
+// It reads JSON or logfmt lines from files or standard input and renders
+// them through [conslog.ConsoleHandler], so terminal output matches what
+// applications print. Lines that cannot be parsed are passed through
+// untouched.
+//
+// Usage:
+//
+//	conslog [flags] [file ...]
+//
+// Flags:
+//
+//	-format name   input format: slog, zap, logrus or zerolog JSON, or logfmt (default slog)
+//	-time-key key  field holding the record time, overrides the format
+//	-level-key key field holding the record level, overrides the format
+//	-msg-key key   field holding the record message, overrides the format
+//	-f             follow files like tail -f, surviving rotation and truncation
+//	-level name    show records at or above level, e.g. warn
+//	-since time    show records at or after time, RFC 3339 or a duration ago like 15m
+//	-until time    show records before time, RFC 3339 or a duration ago
+//	-grep regexp   show records whose message or attribute values match
+//	-filter expr   show records matching an expression, e.g. 'http.status>=500'
+//	-output name   output format: console, compact or json (default console)
+//
+// Record filters hide lines that cannot be parsed, except -grep which
+// matches them as text.
+//
+// Example:
+//
+//	kubectl logs deploy/api | conslog -format zap
+//	conslog -format logfmt app.log
+//	conslog -f -level warn -since 1h -output compact /var/log/api.log
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logparse"
+	"github.com/voler88/conslog/pkg/logquery"
+)
+
+// pollInterval is how often followed files are checked for new data.
+const pollInterval = 250 * time.Millisecond
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("conslog", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "slog", "input format: slog, zap, logrus or zerolog JSON, or logfmt")
+	timeKey := fs.String("time-key", "", "field holding the record time, overrides the format")
+	levelKey := fs.String("level-key", "", "field holding the record level, overrides the format")
+	msgKey := fs.String("msg-key", "", "field holding the record message, overrides the format")
+	follow := fs.Bool("f", false, "follow files like tail -f, surviving rotation and truncation")
+	level := fs.String("level", "", "show records at or above level, e.g. warn")
+	since := fs.String("since", "", "show records at or after time, RFC 3339 or a duration ago like 15m")
+	until := fs.String("until", "", "show records before time, RFC 3339 or a duration ago")
+	grep := fs.String("grep", "", "show records whose message or attribute values match")
+	filter := fs.String("filter", "", "show records matching an expression, e.g. 'http.status>=500'")
+	output := fs.String("output", "console", "output format: console, compact or json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	usageErr := func(format string, args ...any) int {
+		fmt.Fprintf(stderr, "conslog: "+format+"\n", args...)
+		return 2
+	}
+
+	parse := logparse.ParseJSON
+	keys, ok := logparse.FormatKeys(*format)
+	if *format == "logfmt" {
+		parse, keys, ok = logparse.ParseLogfmt, logparse.SlogKeys, true
+	}
+	if !ok {
+		return usageErr("unknown format %q", *format)
+	}
+	for _, o := range []struct{ dst, v *string }{
+		{&keys.Time, timeKey}, {&keys.Level, levelKey}, {&keys.Message, msgKey},
+	} {
+		if *o.v != "" {
+			*o.dst = *o.v
+		}
+	}
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+	p, err := newPrinter(out, *output)
+	if err != nil {
+		return usageErr("%v", err)
+	}
+	p.parse, p.keys = parse, keys
+
+	now := time.Now()
+	if *level != "" {
+		l, err := logparse.ParseLevel(*level)
+		if err != nil {
+			return usageErr("invalid -level: %v", err)
+		}
+		p.minLevel = &l
+	}
+	if p.since, err = parseTimeFlag(*since, now); err != nil {
+		return usageErr("invalid -since: %v", err)
+	}
+	if p.until, err = parseTimeFlag(*until, now); err != nil {
+		return usageErr("invalid -until: %v", err)
+	}
+	if *grep != "" {
+		if p.grep, err = regexp.Compile(*grep); err != nil {
+			return usageErr("invalid -grep: %v", err)
+		}
+	}
+	if *filter != "" {
+		if p.expr, err = logquery.Parse(*filter); err != nil {
+			return usageErr("invalid -filter: %v", err)
+		}
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		code int
+	)
+	for _, name := range files {
+		read := func() error { return p.printFile(name, stdin) }
+		if *follow && name != "-" {
+			read = func() error { return followFile(ctx, name, pollInterval, p.printLine, p.flush) }
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := read(); err != nil && !errors.Is(err, context.Canceled) {
+				mu.Lock()
+				defer mu.Unlock()
+				fmt.Fprintf(stderr, "conslog: %v\n", err)
+				code = 1
+			}
+		}()
+		if !*follow {
+			wg.Wait() // print files one after another
+		}
+	}
+	wg.Wait()
+	return code
+}
+
+// parseTimeFlag parses an RFC 3339 time or a duration before now,
+// the zero time for an empty value.
+func parseTimeFlag(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
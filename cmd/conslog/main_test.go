@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiRe = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// runCmd runs the command and returns uncolorized stdout, stderr and exit code.
+func runCmd(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(t.Context(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return ansiRe.ReplaceAllString(stdout.String(), ""), stderr.String(), code
+}
+
+// TestRun verifies rendering, pass-through and key mappings.
+func TestRun(t *testing.T) {
+	tt := []struct {
+		name  string
+		args  []string
+		input string
+		want  string
+	}{
+		{
+			"Slog",
+			nil,
+			`{"time":"2024-01-02T03:04:05.006Z","level":"WARN","msg":"disk","req":{"id":7}}` + "\n",
+			"[03:04:05.006] WARN: disk\n  req:\n    id: 7\n",
+		},
+		{
+			"PassThrough",
+			nil,
+			"starting...\n[1,2]\n{broken\nno newline",
+			"starting...\n[1,2]\n{broken\nno newline\n",
+		},
+		{
+			"Zerolog",
+			[]string{"-format", "zerolog"},
+			`{"level":"error","time":"2024-01-02T03:04:05Z","message":"failed"}` + "\n",
+			"[03:04:05.000] ERROR: failed\n",
+		},
+		{
+			"Logfmt",
+			[]string{"-format", "logfmt"},
+			`time=2024-01-02T03:04:05.006Z level=ERROR msg="query failed" db.table=users` + "\nplain text\n",
+			"[03:04:05.006] ERROR: query failed\n  db:\n    table: \"users\"\nplain text\n",
+		},
+		{
+			"KeyOverride",
+			[]string{"-format", "zap", "-msg-key", "message", "-level-key", "severity"},
+			`{"severity":"debug","message":"tick","ts":1704164645.5}` + "\n",
+			"DEBUG: tick\n",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			out, errOut, code := runCmd(t, tc.input, tc.args...)
+			if code != 0 || errOut != "" {
+				t.Fatalf("unexpected exit %d: %s", code, errOut)
+			}
+			if tc.name == "KeyOverride" {
+				if !strings.HasSuffix(out, tc.want) || !strings.Contains(out, ".500]") {
+					t.Errorf("expected %q with fractional time, got %q", tc.want, out)
+				}
+				return
+			}
+			if out != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, out)
+			}
+		})
+	}
+}
+
+// TestRunFiles verifies reading files and reporting errors.
+func TestRunFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(`{"level":"INFO","msg":"from file"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, errOut, code := runCmd(t, "", path, filepath.Join(t.TempDir(), "missing.log"))
+	if code != 1 || !strings.Contains(errOut, "missing.log") {
+		t.Errorf("expected error for missing file, got %d: %s", code, errOut)
+	}
+	if out != "INFO: from file\n" {
+		t.Errorf("unexpected output: %q", out)
+	}
+
+	if _, errOut, code := runCmd(t, "", "-format", "log4j"); code != 2 || !strings.Contains(errOut, "unknown format") {
+		t.Errorf("expected usage error, got %d: %s", code, errOut)
+	}
+}
+
+// viewerInput holds records of different levels and times and a plain line.
+const viewerInput = `{"time":"2024-01-02T03:00:00Z","level":"DEBUG","msg":"cache warm","keys":12}
+{"time":"2024-01-02T03:10:00Z","level":"INFO","msg":"request","http":{"status":200,"path":"/users"}}
+plain text line
+{"time":"2024-01-02T03:20:00Z","level":"ERROR","msg":"upstream timeout","http":{"status":504,"path":"/orders"}}
+`
+
+// TestRunFilters verifies level, time range, grep and expression filters.
+func TestRunFilters(t *testing.T) {
+	tt := []struct {
+		name string
+		args []string
+		want []string // expected messages or plain lines
+	}{
+		{"None", nil, []string{"cache warm", "request", "plain text line", "upstream timeout"}},
+		{"Level", []string{"-level", "info"}, []string{"request", "upstream timeout"}},
+		{"Since", []string{"-since", "2024-01-02T03:10:00Z"}, []string{"request", "upstream timeout"}},
+		{"Until", []string{"-until", "2024-01-02T03:10:00Z"}, []string{"cache warm"}},
+		{"GrepMessage", []string{"-grep", "time"}, []string{"upstream timeout"}},
+		{"GrepAttr", []string{"-grep", "path=/users"}, []string{"request"}},
+		{"GrepPlain", []string{"-grep", "plain"}, []string{"plain text line"}},
+		{"Filter", []string{"-filter", "http.status>=500"}, []string{"upstream timeout"}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			out, errOut, code := runCmd(t, viewerInput, append(tc.args, "-output", "compact")...)
+			if code != 0 {
+				t.Fatalf("unexpected exit %d: %s", code, errOut)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+			if len(lines) != len(tc.want) {
+				t.Fatalf("expected %d lines, got:\n%s", len(tc.want), out)
+			}
+			for i, want := range tc.want {
+				if !strings.Contains(lines[i], want) {
+					t.Errorf("line %d: expected %q, got %q", i, want, lines[i])
+				}
+			}
+		})
+	}
+}
+
+// TestRunOutputs verifies compact and JSON output formats.
+func TestRunOutputs(t *testing.T) {
+	input := `{"time":"2024-01-02T03:20:00Z","level":"ERROR","msg":"failed","http":{"status":504},"note":"two words","tags":["a"]}` + "\n"
+
+	out, _, _ := runCmd(t, input, "-output", "compact")
+	if out != `[03:20:00.000] ERROR: failed http.status=504 note="two words" tags=["a"]`+"\n" {
+		t.Errorf("unexpected compact output: %q", out)
+	}
+
+	out, _, _ = runCmd(t, input, "-output", "json")
+	if out != `{"time":"2024-01-02T03:20:00Z","level":"ERROR","msg":"failed","http":{"status":504},"note":"two words","tags":["a"]}`+"\n" {
+		t.Errorf("unexpected JSON output: %q", out)
+	}
+
+	for _, args := range [][]string{
+		{"-output", "xml"}, {"-level", "loud"}, {"-since", "yesterday"},
+		{"-grep", "("}, {"-filter", "a &&"},
+	} {
+		if _, errOut, code := runCmd(t, "", args...); code != 2 || errOut == "" {
+			t.Errorf("%v: expected usage error, got %d", args, code)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/voler88/conslog"
+	"github.com/voler88/conslog/pkg/logparse"
+	"github.com/voler88/conslog/pkg/logquery"
+)
+
+// ANSI escape codes of compact attributes, matching the console handler.
+const (
+	ansiDarkGray = "\033[90m"
+	ansiReset    = "\033[0m"
+)
+
+// allLevels makes handlers render records of every level.
+var allLevels = &slog.HandlerOptions{Level: slog.Level(math.MinInt)}
+
+// printer filters log lines and renders them in the output format.
+// It is safe for concurrent use by followed files.
+type printer struct {
+	mu     sync.Mutex
+	out    *bufio.Writer
+	render func(slog.Record) error
+	parse  func([]byte, logparse.Keys) (slog.Record, error)
+	keys   logparse.Keys
+
+	minLevel *slog.Level // nil shows all levels
+	since    time.Time   // zero shows records from the beginning
+	until    time.Time   // zero shows records up to the end
+	grep     *regexp.Regexp
+	expr     *logquery.Expr
+}
+
+// newPrinter returns a printer writing to out in the named output format.
+func newPrinter(out *bufio.Writer, output string) (*printer, error) {
+	p := &printer{out: out, parse: logparse.ParseJSON, keys: logparse.SlogKeys}
+	switch output {
+	case "console":
+		h := conslog.NewConsoleHandler(out, allLevels)
+		p.render = func(r slog.Record) error { return h.Handle(context.Background(), r) }
+	case "compact":
+		var buf bytes.Buffer
+		h := conslog.NewConsoleHandler(&buf, allLevels)
+		p.render = func(r slog.Record) error {
+			buf.Reset()
+			header := slog.NewRecord(r.Time, r.Level, r.Message, 0)
+			if err := h.Handle(context.Background(), header); err != nil {
+				return err
+			}
+			buf.Truncate(buf.Len() - 1) // trailing newline
+			for _, a := range flatAttrs(r) {
+				buf.WriteString(" " + ansiDarkGray + a.Key + "=" + compactValue(a.Value) + ansiReset)
+			}
+			buf.WriteByte('\n')
+			_, err := out.Write(buf.Bytes())
+			return err
+		}
+	case "json":
+		h := slog.NewJSONHandler(out, allLevels)
+		p.render = func(r slog.Record) error { return h.Handle(context.Background(), r) }
+	default:
+		return nil, fmt.Errorf("unknown output %q: must be one of console, compact, json", output)
+	}
+	return p, nil
+}
+
+// flatAttrs returns the attributes of r with dot separated group paths as keys.
+func flatAttrs(r slog.Record) []slog.Attr {
+	var attrs []slog.Attr
+	var flatten func(prefix string, a slog.Attr)
+	flatten = func(prefix string, a slog.Attr) {
+		if a.Value.Kind() != slog.KindGroup {
+			attrs = append(attrs, slog.Attr{Key: prefix + a.Key, Value: a.Value})
+			return
+		}
+		for _, ga := range a.Value.Group() {
+			flatten(prefix+a.Key+".", ga)
+		}
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		flatten("", a)
+		return true
+	})
+	return attrs
+}
+
+// compactValue formats a value on a single line, quoting strings with
+// spaces or special characters and encoding arrays and maps as JSON.
+func compactValue(v slog.Value) string {
+	if v.Kind() == slog.KindAny {
+		if b, err := json.Marshal(v.Any()); err == nil {
+			return string(b)
+		}
+	}
+	s := v.String()
+	if s == "" || strings.ContainsFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '"' || r == '=' || r == 0x7f
+	}) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// filtersRecords reports whether filters apply to records only,
+// hiding lines that cannot be parsed.
+func (p *printer) filtersRecords() bool {
+	return p.minLevel != nil || !p.since.IsZero() || !p.until.IsZero() || p.expr != nil
+}
+
+// show reports whether a record passes all filters.
+func (p *printer) show(r slog.Record) bool {
+	if p.minLevel != nil && r.Level < *p.minLevel {
+		return false
+	}
+	if !p.since.IsZero() && (r.Time.IsZero() || r.Time.Before(p.since)) {
+		return false
+	}
+	if !p.until.IsZero() && (r.Time.IsZero() || !r.Time.Before(p.until)) {
+		return false
+	}
+	if p.expr != nil && !p.expr.MatchRecord(r) {
+		return false
+	}
+	if p.grep != nil && !p.grep.MatchString(r.Message) {
+		for _, a := range flatAttrs(r) {
+			if p.grep.MatchString(a.Key + "=" + a.Value.String()) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// printLine renders a single line, passing through lines that cannot be
+// parsed unless record filters are set.
+func (p *printer) printLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rec, err := p.parse(line, p.keys)
+	if err == nil {
+		if !p.show(rec) {
+			return nil
+		}
+		return p.render(rec)
+	}
+
+	if p.filtersRecords() || (p.grep != nil && !p.grep.Match(line)) {
+		return nil
+	}
+	if _, err := p.out.Write(line); err != nil {
+		return err
+	}
+	if line[len(line)-1] != '\n' {
+		return p.out.WriteByte('\n')
+	}
+	return nil
+}
+
+// flush writes buffered output.
+func (p *printer) flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.out.Flush()
+}
+
+// printFile prints the lines of a file, "-" reads stdin.
+func (p *printer) printFile(name string, stdin io.Reader) error {
+	if name == "-" {
+		return p.print(stdin)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return p.print(f)
+}
+
+// print prints each line of r.
+func (p *printer) print(r io.Reader) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if werr := p.printLine(line); werr != nil {
+				return werr
+			}
+		}
+		if br.Buffered() == 0 {
+			// flush while waiting for input so piped streams show up promptly
+			if ferr := p.flush(); ferr != nil {
+				return ferr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
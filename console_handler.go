@@ -3,7 +3,14 @@ Package conslog provides a high-performance, colorized console handler for [slog
 Features include:
 - thread-safe logging with mutex-protected writes
 - colorized output with ANSI escape codes
-- pretty-printed JSON for complex values
+- native rendering of durations, times, byte slices and [fmt.Stringer] values
+- multi-line strings rendered as indented blocks
+- error rendering with wrapped causes, attributes and stack traces
+- optional stack trace capture for records at or above a level
+- injectable clock for deterministic timestamps
+- short trace ID next to the level for records with a [TraceIDKey] attribute
+- single-line logfmt output with [LogfmtHandler]
+- pretty-printed JSON for other complex values
 - pooled resources to minimize allocations
 - lazy-initialized indentation cache
 
@@ -21,14 +28,19 @@ package conslog
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"net/url"
+	"reflect"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 const timeFormat = "[15:04:05.000]"
@@ -108,6 +120,24 @@ func getIndent(indent int) string {
 	return strings.Repeat("  ", indent) // fallback for deep nesting
 }
 
+// levelColor returns the ANSI color of a log level.
+func levelColor(l slog.Level) string {
+	switch {
+	case l <= slog.LevelDebug:
+		return ansiLightGray
+	case l <= slog.LevelInfo:
+		return ansiCyan
+	case l < slog.LevelWarn:
+		return ansiLightBlue
+	case l < slog.LevelError:
+		return ansiLightYellow
+	case l <= slog.LevelError+1:
+		return ansiLightRed
+	default:
+		return ansiLightMagenta
+	}
+}
+
 // normalizeKey replaces empty keys with a quoted empty string
 // to ensure keys are never empty in the output.
 func normalizeKey(key string) string {
@@ -117,24 +147,75 @@ func normalizeKey(key string) string {
 	return key
 }
 
+// Block is a string value that [ConsoleHandler] renders as an indented block
+// below its key, e.g. SQL queries or templates. Other handlers treat it as
+// a plain string.
+type Block string
+
+// baseIndent is the indentation level of top-level attributes.
+const baseIndent = 1
+
+// TraceIDKey is the key of the trace ID attribute. [ConsoleHandler] renders
+// top-level trace IDs shortened next to the level instead of as attribute.
+const TraceIDKey = "trace_id"
+
+// shortTraceID is the number of trace ID characters rendered by [ConsoleHandler].
+const shortTraceID = 8
+
 // ConsoleHandler implements [slog.Handler] for colorized terminal output.
 type ConsoleHandler struct {
 	opts           slog.HandlerOptions // slog configuration
+	stackLevel     slog.Leveler        // minimum level for stack capture, nil disables it
+	clock          Clock               // source of record timestamps, nil keeps record time
 	indent         int                 // current indentation level
 	unopenedGroups []string            // pending group names to indent
+	traceID        string              // trace ID from WithAttrs, rendered next to the level
 	preBuf         strings.Builder     // buffered attributes from WithAttrs/WithGroup
 	mu             *sync.Mutex         // protects writes to output
 	w              io.Writer           // output destination
 }
 
+// Option configures [ConsoleHandler] features beyond [slog.HandlerOptions].
+type Option func(*ConsoleHandler)
+
+// WithStackTrace enables capturing the goroutine stack at the log call
+// for records at or above level, rendered after the record attributes.
+func WithStackTrace(level slog.Leveler) Option {
+	return func(h *ConsoleHandler) {
+		h.stackLevel = level
+	}
+}
+
+// Clock provides the current time, e.g. a fake clock for deterministic tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the [Clock] interface.
+type ClockFunc func() time.Time
+
+// Now returns the result of f, implements [Clock] interface.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// WithClock stamps records with the time of c instead of the time set by
+// [slog.Logger]. Records with a zero time are still rendered without one.
+func WithClock(c Clock) Option {
+	return func(h *ConsoleHandler) {
+		h.clock = c
+	}
+}
+
 // NewConsoleHandler returns new [ConsoleHandler] instance.
 // opts: optional handler configuration (nil uses defaults)
 // w: output writer (e.g., os.Stderr, os.Stdout)
-func NewConsoleHandler(w io.Writer, opts *slog.HandlerOptions) *ConsoleHandler {
+// options: optional console specific features
+func NewConsoleHandler(w io.Writer, opts *slog.HandlerOptions, options ...Option) *ConsoleHandler {
 	h := &ConsoleHandler{
 		w:      w,
 		mu:     new(sync.Mutex),
-		indent: 1, // base indentation level
+		indent: baseIndent,
 	}
 
 	if opts != nil {
@@ -143,6 +224,9 @@ func NewConsoleHandler(w io.Writer, opts *slog.HandlerOptions) *ConsoleHandler {
 	if h.opts.Level == nil {
 		h.opts.Level = slog.LevelInfo // default to Info level
 	}
+	for _, opt := range options {
+		opt(h)
+	}
 
 	return h
 }
@@ -162,29 +246,30 @@ func (h *ConsoleHandler) Handle(ctx context.Context, r slog.Record) error {
 
 	// format timestamp if present
 	if !r.Time.IsZero() {
+		if h.clock != nil {
+			r.Time = h.clock.Now()
+		}
 		b.WriteString(colorize(ansiLightGray, r.Time.Format(timeFormat)))
 		b.WriteByte(' ')
 	}
 
 	// format log level with color coding
-	levelStr := r.Level.String() + ":"
-	switch {
-	case r.Level <= slog.LevelDebug:
-		levelStr = colorize(ansiLightGray, levelStr)
-	case r.Level <= slog.LevelInfo:
-		levelStr = colorize(ansiCyan, levelStr)
-	case r.Level < slog.LevelWarn:
-		levelStr = colorize(ansiLightBlue, levelStr)
-	case r.Level < slog.LevelError:
-		levelStr = colorize(ansiLightYellow, levelStr)
-	case r.Level <= slog.LevelError+1:
-		levelStr = colorize(ansiLightRed, levelStr)
-	default:
-		levelStr = colorize(ansiLightMagenta, levelStr)
-	}
-	b.WriteString(levelStr)
+	b.WriteString(colorize(levelColor(r.Level), r.Level.String()+":"))
 	b.WriteByte(' ')
 
+	// format short trace ID, record attributes override WithAttrs
+	traceID := h.traceID
+	r.Attrs(func(a slog.Attr) bool {
+		if id, ok := h.traceAttr(a); ok {
+			traceID = id
+		}
+		return true
+	})
+	if traceID != "" {
+		b.WriteString(colorize(ansiDarkGray, "["+traceID[:min(len(traceID), shortTraceID)]+"]"))
+		b.WriteByte(' ')
+	}
+
 	// format message and pre-buffered attributes
 	b.WriteString(colorize(ansiWhite, r.Message))
 	b.WriteByte('\n')
@@ -194,11 +279,22 @@ func (h *ConsoleHandler) Handle(ctx context.Context, r slog.Record) error {
 	if r.NumAttrs() > 0 {
 		h.appendUnopenedGroups(b, h.indent)
 		r.Attrs(func(a slog.Attr) bool {
-			h.appendAttr(b, a, h.indent+len(h.unopenedGroups))
+			if _, ok := h.traceAttr(a); !ok {
+				h.appendAttr(b, a, h.indent+len(h.unopenedGroups))
+			}
 			return true
 		})
 	}
 
+	// capture stack trace of the log call if enabled for this level
+	if h.stackLevel != nil && r.Level >= h.stackLevel.Level() {
+		if frames := callerStack(r.PC); len(frames) > 0 {
+			b.WriteString(getIndent(baseIndent))
+			b.WriteString(colorize(ansiDarkGray, "stack:\n"))
+			appendStack(b, frames, baseIndent+1)
+		}
+	}
+
 	// write final output with mutex protection
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -230,6 +326,10 @@ func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	h2.unopenedGroups = nil
 
 	for _, a := range attrs {
+		if id, ok := h2.traceAttr(a); ok {
+			h2.traceID = id
+			continue
+		}
 		h2.appendAttr(&h2.preBuf, a, h2.indent)
 	}
 
@@ -251,6 +351,18 @@ func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
 	return &h2
 }
 
+// traceAttr returns the value of a top-level string attribute with [TraceIDKey].
+func (h *ConsoleHandler) traceAttr(a slog.Attr) (string, bool) {
+	if a.Key != TraceIDKey || h.indent != baseIndent || len(h.unopenedGroups) > 0 {
+		return "", false
+	}
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindString || v.String() == "" {
+		return "", false
+	}
+	return v.String(), true
+}
+
 // appendUnopenedGroups flushes pending groups to the buffer.
 func (h *ConsoleHandler) appendUnopenedGroups(b *strings.Builder, indent int) {
 	for _, g := range h.unopenedGroups {
@@ -287,12 +399,57 @@ func (h *ConsoleHandler) appendAttr(b *strings.Builder, a slog.Attr, indent int)
 	case slog.KindString:
 		key := normalizeKey(a.Key)
 
+		if v := a.Value.String(); strings.Contains(v, "\n") {
+			appendBlock(b, prefix, key, v, indent)
+			return
+		}
+
 		b.WriteString(prefix)
 		b.WriteString(colorize(ansiDarkGray, key+": \""+a.Value.String()+"\"\n"))
 
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64, slog.KindBool:
+		appendValue(b, prefix, normalizeKey(a.Key), a.Value.String())
+
+	case slog.KindDuration:
+		appendValue(b, prefix, normalizeKey(a.Key), a.Value.Duration().String())
+
+	case slog.KindTime:
+		appendValue(b, prefix, normalizeKey(a.Key), a.Value.Time().Format(time.RFC3339Nano))
+
 	default:
 		key := normalizeKey(a.Key)
 
+		// render well-known types natively before falling back to JSON
+		switch v := a.Value.Any().(type) {
+		case error:
+			h.appendError(b, key, v, indent)
+			return
+		case []byte:
+			appendBytes(b, prefix, key, v, indent)
+			return
+		case Block:
+			appendBlock(b, prefix, key, string(v), indent)
+			return
+		case stackTrace:
+			if len(v) == 0 {
+				return // skip empty stacks
+			}
+			b.WriteString(prefix)
+			b.WriteString(colorize(ansiDarkGray, key+":\n"))
+			appendStack(b, v, indent+1)
+			return
+		case url.URL:
+			appendValue(b, prefix, key, v.String())
+			return
+		case fmt.Stringer:
+			if isNilPointer(v) {
+				appendValue(b, prefix, key, "<nil>")
+			} else {
+				appendValue(b, prefix, key, v.String())
+			}
+			return
+		}
+
 		b.WriteString(prefix)
 
 		// use pooled encoder for JSON formatting
@@ -311,3 +468,48 @@ func (h *ConsoleHandler) appendAttr(b *strings.Builder, a slog.Attr, indent int)
 		b.WriteByte('\n')
 	}
 }
+
+// appendValue writes a single "key: value" line with the given prefix.
+func appendValue(b *strings.Builder, prefix, key, value string) {
+	b.WriteString(prefix)
+	b.WriteString(colorize(ansiDarkGray, key+": "+value+"\n"))
+}
+
+// appendBlock writes a multi-line string as a "key: |" line
+// followed by its lines indented one level deeper.
+func appendBlock(b *strings.Builder, prefix, key, v string, indent int) {
+	appendValue(b, prefix, key, "|")
+	appendLines(b, v, indent+1)
+}
+
+// appendLines writes each line of a multi-line string at indent.
+func appendLines(b *strings.Builder, v string, indent int) {
+	linePrefix := getIndent(indent)
+	for line := range strings.Lines(strings.TrimRight(v, "\n")) {
+		b.WriteString(linePrefix)
+		b.WriteString(colorize(ansiDarkGray, strings.TrimSuffix(line, "\n")))
+		b.WriteByte('\n')
+	}
+}
+
+// appendBytes writes a byte slice as its length followed by
+// an indented hex dump, one line per 16 bytes.
+func appendBytes(b *strings.Builder, prefix, key string, v []byte, indent int) {
+	appendValue(b, prefix, key, "["+strconv.Itoa(len(v))+" bytes]")
+	if len(v) == 0 {
+		return
+	}
+
+	dumpPrefix := getIndent(indent + 1)
+	for line := range strings.Lines(hex.Dump(v)) {
+		b.WriteString(dumpPrefix)
+		b.WriteString(colorize(ansiDarkGray, line))
+	}
+}
+
+// isNilPointer reports whether v holds a nil pointer, so methods
+// with pointer receivers are not called on it.
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
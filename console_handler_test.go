@@ -3,8 +3,13 @@ package conslog_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"net"
+	"net/url"
+	"os"
 	"regexp"
 	"runtime"
 	"strconv"
@@ -14,6 +19,7 @@ import (
 	"time"
 
 	"github.com/voler88/conslog"
+	"github.com/voler88/conslog/pkg/logtest"
 )
 
 const (
@@ -399,3 +405,225 @@ func TestPanicOnInvalidType(t *testing.T) {
 		t.Fatalf("Handle returned error: %v", err)
 	}
 }
+
+// TestNativeValueRendering checks that common Go types are rendered natively instead of JSON.
+func TestNativeValueRendering(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 500, time.UTC)
+	u, _ := url.Parse("https://example.com/path?q=1")
+
+	tests := []struct {
+		name   string
+		attr   slog.Attr
+		expect []string
+	}{
+		{"Int", slog.Int("n", -42), []string{"n: -42"}},
+		{"Uint", slog.Uint64("n", 42), []string{"n: 42"}},
+		{"Float", slog.Float64("f", 1.5), []string{"f: 1.5"}},
+		{"FloatNaN", slog.Float64("f", math.NaN()), []string{"f: NaN"}},
+		{"Bool", slog.Bool("ok", true), []string{"ok: true"}},
+		{"Duration", slog.Duration("took", 1500*time.Millisecond), []string{"took: 1.5s"}},
+		{"Time", slog.Time("at", ts), []string{"at: 2024-05-01T12:30:00.0000005Z"}},
+		{"Error", slog.Any("err", errors.New("boom")), []string{`err: "boom"`}},
+		{"IP", slog.Any("ip", net.ParseIP("10.0.0.1")), []string{"ip: 10.0.0.1"}},
+		{"URLPointer", slog.Any("url", u), []string{"url: https://example.com/path?q=1"}},
+		{"URLValue", slog.Any("url", *u), []string{"url: https://example.com/path?q=1"}},
+		{"NilStringer", slog.Any("url", (*url.URL)(nil)), []string{"url: <nil>"}},
+		{"NilError", slog.Any("err", (*os.PathError)(nil)), []string{"err: \"<nil>\""}},
+		{
+			"Bytes",
+			slog.Any("raw", []byte("hello")),
+			[]string{"raw: [5 bytes]", "    00000000  68 65 6c 6c 6f", "|hello|"},
+		},
+		{"EmptyBytes", slog.Any("raw", []byte{}), []string{"raw: [0 bytes]"}},
+		{
+			"MultilineString",
+			slog.String("body", "first\nsecond\n"),
+			[]string{"  body: |\n    first\n    second\n"},
+		},
+		{
+			"Block",
+			slog.Any("query", conslog.Block("SELECT 1")),
+			[]string{"  query: |\n    SELECT 1\n"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			h := conslog.NewConsoleHandler(&buf, nil)
+
+			pc, _, _, _ := runtime.Caller(0)
+			r := slog.NewRecord(time.Now(), slog.LevelInfo, "test", pc)
+			r.AddAttrs(tc.attr)
+
+			if err := h.Handle(context.Background(), r); err != nil {
+				t.Fatalf("Handle failed: %v", err)
+			}
+
+			plain := uncolorize(t, buf.String())
+			for _, want := range tc.expect {
+				if !strings.Contains(plain, want) {
+					t.Errorf("expected output to contain %q, got %q", want, plain)
+				}
+			}
+		})
+	}
+}
+
+// TestGoldenOutput compares rendering of canonical records with golden files
+// in testdata/console, set CONSLOG_UPDATE_GOLDEN=1 to accept formatting
+// changes.
+func TestGoldenOutput(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6_000_000, time.UTC)
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug - 4}
+
+	tests := []struct {
+		name  string
+		setup func(slog.Handler) slog.Handler
+		recs  func() []slog.Record
+	}{
+		{
+			"levels",
+			nil,
+			func() []slog.Record {
+				var recs []slog.Record
+				for _, l := range []slog.Level{
+					slog.LevelDebug - 4, slog.LevelDebug, slog.LevelInfo, slog.LevelInfo + 2,
+					slog.LevelWarn, slog.LevelError, slog.LevelError + 4,
+				} {
+					recs = append(recs, slog.NewRecord(ts, l, "level "+l.String(), 0))
+				}
+				return recs
+			},
+		},
+		{
+			"groups",
+			nil,
+			func() []slog.Record {
+				r := slog.NewRecord(ts, slog.LevelInfo, "request", 0)
+				r.AddAttrs(
+					slog.Group("req",
+						slog.String("method", "GET"),
+						slog.Group("headers", slog.String("accept", "application/json")),
+						slog.Group("empty"),
+					),
+					slog.Group("", slog.Int("inlined", 1)),
+					slog.Int("status", 200),
+				)
+				return []slog.Record{r}
+			},
+		},
+		{
+			"with_attrs",
+			func(h slog.Handler) slog.Handler {
+				return h.WithAttrs([]slog.Attr{slog.String("app", "api")}).
+					WithGroup("req").
+					WithAttrs([]slog.Attr{slog.String("id", "42")}).
+					WithGroup("db")
+			},
+			func() []slog.Record {
+				r1 := slog.NewRecord(ts, slog.LevelInfo, "query", 0)
+				r1.AddAttrs(slog.String("table", "users"), slog.Int("rows", 3))
+				r2 := slog.NewRecord(ts, slog.LevelInfo, "no attributes", 0)
+				return []slog.Record{r1, r2}
+			},
+		},
+		{
+			"maps",
+			nil,
+			func() []slog.Record {
+				r := slog.NewRecord(ts, slog.LevelDebug, "config", 0)
+				r.AddAttrs(
+					slog.Any("env", map[string]any{"VERBOSITY": 2, "AUTH": true, "TAGS": []string{"a", "b"}}),
+					slog.Any("empty", map[string]int{}),
+					slog.Any("list", []int{1, 2, 3}),
+				)
+				return []slog.Record{r}
+			},
+		},
+		{
+			"errors",
+			nil,
+			func() []slog.Record {
+				notFound := &richError{msg: "not found", attrs: []slog.Attr{slog.String("path", "/etc/app.yaml")}}
+				err := fmt.Errorf("load config: %w", errors.Join(notFound, errors.New("permission denied")))
+				r := slog.NewRecord(ts, slog.LevelError, "startup failed", 0)
+				r.AddAttrs(slog.Any("err", err), slog.Any("simple", errors.New("boom")))
+				return []slog.Record{r}
+			},
+		},
+		{
+			"values",
+			nil,
+			func() []slog.Record {
+				u, _ := url.Parse("https://example.com/path?q=1")
+				r := slog.NewRecord(ts, slog.LevelInfo, "values", 0)
+				r.AddAttrs(
+					slog.Duration("took", 1500*time.Millisecond),
+					slog.Time("at", ts),
+					slog.Any("raw", []byte("hello, world")),
+					slog.String("body", "first\nsecond"),
+					slog.Any("url", u),
+					slog.Float64("ratio", 0.25),
+					slog.Bool("ok", true),
+				)
+				return []slog.Record{r}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var h slog.Handler = conslog.NewConsoleHandler(&buf, opts)
+			if tc.setup != nil {
+				h = tc.setup(h)
+			}
+			for _, r := range tc.recs() {
+				if err := h.Handle(context.Background(), r); err != nil {
+					t.Fatalf("Handle failed: %v", err)
+				}
+			}
+			logtest.GoldenANSI(t, "console/"+tc.name, buf.Bytes())
+		})
+	}
+}
+
+// TestWithClock verifies that record timestamps come from the clock.
+func TestWithClock(t *testing.T) {
+	var buf bytes.Buffer
+	fixed := time.Date(2024, 1, 2, 3, 4, 5, 6_000_000, time.Local)
+	h := conslog.NewConsoleHandler(&buf, nil, conslog.WithClock(conslog.ClockFunc(func() time.Time { return fixed })))
+
+	slog.New(h).Info("stamped")
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "untimed", 0)); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	out := uncolorize(t, buf.String())
+	if out != "[03:04:05.006] INFO: stamped\n"+"INFO: untimed\n" {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
+
+// TestTraceID verifies the short trace ID next to the level.
+func TestTraceID(t *testing.T) {
+	var buf bytes.Buffer
+	fixed := time.Date(2024, 1, 2, 3, 4, 5, 6_000_000, time.Local)
+	l := slog.New(conslog.NewConsoleHandler(&buf, nil, conslog.WithClock(conslog.ClockFunc(func() time.Time { return fixed }))))
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	l.With(conslog.TraceIDKey, traceID).Info("from handler", "span_id", "00f067aa0ba902b7")
+	l.Info("from record", conslog.TraceIDKey, "0af7651916cd43dd8448eb211c80319c")
+	l.WithGroup("g").Info("grouped", conslog.TraceIDKey, traceID)
+
+	want := "[03:04:05.006] INFO: [4bf92f35] from handler\n" +
+		"  span_id: \"00f067aa0ba902b7\"\n" +
+		"[03:04:05.006] INFO: [0af76519] from record\n" +
+		"[03:04:05.006] INFO: grouped\n" +
+		"  g:\n" +
+		"    trace_id: \"4bf92f3577b34da6a3ce929d0e0e4736\"\n"
+	if out := uncolorize(t, buf.String()); out != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, out)
+	}
+}
@@ -0,0 +1,179 @@
+package conslog
+
+import (
+	"log/slog"
+	"maps"
+	"strings"
+)
+
+// maxErrorDepth limits how deep error chains are followed,
+// protecting against cyclic or pathologically long chains.
+const maxErrorDepth = 32
+
+// StackTracer is implemented by errors that record the call stack
+// where they were created, as returned by [runtime.Callers].
+type StackTracer interface {
+	StackTrace() []uintptr
+}
+
+// AttrCarrier is implemented by errors that carry structured
+// attributes describing the failure.
+type AttrCarrier interface {
+	Attrs() []slog.Attr
+}
+
+// unwrapErrors returns the direct causes of err, supporting both
+// single (errors.Unwrap) and multiple (errors.Join) wrapping.
+func unwrapErrors(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		return u.Unwrap()
+	case interface{ Unwrap() error }:
+		if cause := u.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	}
+	return nil
+}
+
+// errorMessage returns the message of err, "<nil>" for nil pointers
+// whose Error method would panic.
+func errorMessage(err error) string {
+	if isNilPointer(err) {
+		return "<nil>"
+	}
+	return err.Error()
+}
+
+// errorAttrs returns attributes attached to err itself, not its causes.
+func errorAttrs(err error) []slog.Attr {
+	if c, ok := err.(AttrCarrier); ok {
+		return c.Attrs()
+	}
+	return nil
+}
+
+// errorStack returns the stack trace attached to err itself, not its causes.
+func errorStack(err error) []uintptr {
+	if st, ok := err.(StackTracer); ok {
+		return st.StackTrace()
+	}
+	return nil
+}
+
+// appendError writes an error message followed by its attributes,
+// stack trace and the tree of wrapped causes. Multi-line messages,
+// e.g. of joined errors, are written as blocks indented below the details.
+func (h *ConsoleHandler) appendError(b *strings.Builder, key string, err error, indent int) {
+	if msg := errorMessage(err); strings.Contains(msg, "\n") {
+		appendValue(b, getIndent(indent), key, "|")
+		appendLines(b, msg, indent+2)
+	} else {
+		appendValue(b, getIndent(indent), key, `"`+msg+`"`)
+	}
+	h.appendErrorDetails(b, err, indent+1, 0)
+}
+
+// appendErrorDetails writes everything below an error message line.
+func (h *ConsoleHandler) appendErrorDetails(b *strings.Builder, err error, indent, depth int) {
+	if isNilPointer(err) {
+		return
+	}
+	for _, a := range errorAttrs(err) {
+		h.appendAttr(b, a, indent)
+	}
+
+	if frames := resolveFrames(errorStack(err)); len(frames) > 0 {
+		b.WriteString(getIndent(indent))
+		b.WriteString(colorize(ansiDarkGray, "stack:\n"))
+		appendStack(b, frames, indent+1)
+	}
+
+	causes := unwrapErrors(err)
+	if len(causes) == 0 || depth >= maxErrorDepth {
+		return
+	}
+
+	b.WriteString(getIndent(indent))
+	b.WriteString(colorize(ansiDarkGray, "caused by:\n"))
+	for _, cause := range causes {
+		if cause == nil {
+			continue
+		}
+		b.WriteString(getIndent(indent + 1))
+		if msg := errorMessage(cause); strings.Contains(msg, "\n") {
+			b.WriteString(colorize(ansiDarkGray, "- |\n"))
+			appendLines(b, msg, indent+3)
+		} else {
+			b.WriteString(colorize(ansiDarkGray, `- "`+msg+`"`+"\n"))
+		}
+		h.appendErrorDetails(b, cause, indent+2, depth+1)
+	}
+}
+
+// errorDetail is the structured form of an error used for JSON output.
+type errorDetail struct {
+	Msg    string         `json:"msg"`
+	Attrs  map[string]any `json:"attrs,omitempty"`
+	Stack  stackTrace     `json:"stack,omitempty"`
+	Causes []errorDetail  `json:"causes,omitempty"`
+}
+
+// ErrorValue returns a structured representation of err suitable for
+// JSON handlers, with its message, attributes, stack trace and causes.
+// It can be used from [slog.HandlerOptions.ReplaceAttr] to expand errors.
+func ErrorValue(err error) slog.Value {
+	if err == nil {
+		return slog.AnyValue(nil)
+	}
+	return slog.AnyValue(newErrorDetail(err, 0))
+}
+
+// newErrorDetail recursively builds the structured form of err.
+func newErrorDetail(err error, depth int) errorDetail {
+	if isNilPointer(err) {
+		return errorDetail{Msg: errorMessage(err)}
+	}
+	d := errorDetail{
+		Msg:   err.Error(),
+		Attrs: attrsToMap(errorAttrs(err)),
+		Stack: resolveFrames(errorStack(err)),
+	}
+	if depth >= maxErrorDepth {
+		return d
+	}
+	for _, cause := range unwrapErrors(err) {
+		if cause != nil {
+			d.Causes = append(d.Causes, newErrorDetail(cause, depth+1))
+		}
+	}
+	return d
+}
+
+// attrsToMap converts attributes into a map, turning groups into nested maps.
+func attrsToMap(attrs []slog.Attr) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		switch v.Kind() {
+		case slog.KindGroup:
+			if a.Key == "" {
+				// inline attributes of groups without a key
+				maps.Copy(m, attrsToMap(v.Group()))
+				continue
+			}
+			m[a.Key] = attrsToMap(v.Group())
+		default:
+			if err, ok := v.Any().(error); ok {
+				m[a.Key] = newErrorDetail(err, 0)
+				continue
+			}
+			m[a.Key] = v.Any()
+		}
+	}
+	return m
+}
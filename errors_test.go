@@ -0,0 +1,183 @@
+package conslog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog"
+)
+
+// richError is a test error carrying attributes and a stack trace.
+type richError struct {
+	msg   string
+	attrs []slog.Attr
+	stack []uintptr
+}
+
+func (e *richError) Error() string         { return e.msg }
+func (e *richError) Attrs() []slog.Attr    { return e.attrs }
+func (e *richError) StackTrace() []uintptr { return e.stack }
+
+// newRichError returns a [richError] with the stack of its caller.
+func newRichError(msg string, attrs ...slog.Attr) *richError {
+	pcs := make([]uintptr, 8)
+	n := runtime.Callers(2, pcs)
+	return &richError{msg: msg, attrs: attrs, stack: pcs[:n]}
+}
+
+// handleError logs err under the "err" key and returns uncolorized output.
+func handleError(t *testing.T, err error) string {
+	t.Helper()
+	var buf bytes.Buffer
+	h := conslog.NewConsoleHandler(&buf, nil)
+
+	pc, _, _, _ := runtime.Caller(0)
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "test", pc)
+	r.AddAttrs(slog.Any("err", err))
+
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	return uncolorize(t, buf.String())
+}
+
+// TestErrorRendering checks error messages, cause trees, attributes and stack traces.
+func TestErrorRendering(t *testing.T) {
+	base := errors.New("no such file")
+
+	tests := []struct {
+		name   string
+		err    error
+		expect []string
+	}{
+		{
+			name:   "Plain",
+			err:    base,
+			expect: []string{`  err: "no such file"` + "\n"},
+		},
+		{
+			name: "WrappedChain",
+			err:  fmt.Errorf("load config: %w", fmt.Errorf("open: %w", base)),
+			expect: []string{
+				`  err: "load config: open: no such file"`,
+				"    caused by:\n",
+				`      - "open: no such file"`,
+				"        caused by:\n",
+				`          - "no such file"`,
+			},
+		},
+		{
+			name: "Joined",
+			err:  errors.Join(errors.New("first"), errors.New("second")),
+			expect: []string{
+				"    caused by:\n",
+				`      - "first"`,
+				`      - "second"`,
+			},
+		},
+		{
+			name: "MultiLine",
+			err:  fmt.Errorf("load: %w", errors.Join(errors.New("first"), errors.New("second"))),
+			expect: []string{
+				"  err: |\n      load: first\n      second\n    caused by:\n",
+				"      - |\n          first\n          second\n        caused by:\n",
+			},
+		},
+		{
+			name: "NilPointerCause",
+			err:  fmt.Errorf("open: %w", (*richError)(nil)),
+			expect: []string{
+				`  err: "open: <nil>"`,
+				"    caused by:\n",
+				`      - "<nil>"`,
+			},
+		},
+		{
+			name: "AttrsAndStack",
+			err:  newRichError("failed", slog.String("path", "/etc/app"), slog.Int("retries", 3)),
+			expect: []string{
+				`  err: "failed"`,
+				`    path: "/etc/app"`,
+				"    retries: 3",
+				"    stack:\n",
+				"      github.com/voler88/conslog_test.TestErrorRendering()",
+				"errors_test.go:",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			plain := handleError(t, tc.err)
+			for _, want := range tc.expect {
+				if !strings.Contains(plain, want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, plain)
+				}
+			}
+		})
+	}
+}
+
+// TestErrorValue checks the structured JSON form of errors.
+func TestErrorValue(t *testing.T) {
+	err := fmt.Errorf("outer: %w", newRichError("inner", slog.String("id", "42")))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if e, ok := a.Value.Any().(error); ok {
+				a.Value = conslog.ErrorValue(e)
+			}
+			return a
+		},
+	}))
+	logger.Error("failed", "err", err)
+
+	var line struct {
+		Err struct {
+			Msg    string `json:"msg"`
+			Causes []struct {
+				Msg   string         `json:"msg"`
+				Attrs map[string]any `json:"attrs"`
+				Stack []struct {
+					Function string `json:"function"`
+					File     string `json:"file"`
+					Line     int    `json:"line"`
+				} `json:"stack"`
+			} `json:"causes"`
+		} `json:"err"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("failed to parse log line: %v", err)
+	}
+
+	if line.Err.Msg != "outer: inner" {
+		t.Errorf("expected msg %q, got %q", "outer: inner", line.Err.Msg)
+	}
+	if len(line.Err.Causes) != 1 {
+		t.Fatalf("expected 1 cause, got %d: %s", len(line.Err.Causes), buf.String())
+	}
+	cause := line.Err.Causes[0]
+	if cause.Msg != "inner" || cause.Attrs["id"] != "42" {
+		t.Errorf("unexpected cause: %+v", cause)
+	}
+	if len(cause.Stack) == 0 || cause.Stack[0].Line == 0 || cause.Stack[0].File == "" {
+		t.Errorf("expected resolved stack frames, got %+v", cause.Stack)
+	}
+
+	if !conslog.ErrorValue(nil).Equal(slog.AnyValue(nil)) {
+		t.Error("ErrorValue(nil) should return a nil value")
+	}
+	data, _ := json.Marshal(conslog.ErrorValue((*richError)(nil)).Any())
+	if string(data) != `{"msg":"\u003cnil\u003e"}` {
+		t.Errorf("expected nil pointer message only, got %s", data)
+	}
+}
@@ -0,0 +1,205 @@
+package conslog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// logfmtTimeFormat matches the time format of [slog.TextHandler].
+const logfmtTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// LogfmtHandler implements [slog.Handler] for colorized logfmt output,
+// one record per line as key=value pairs with dotted group prefixes.
+// Output without colors can be read by logfmt tools, keys and values are
+// quoted when they are empty or contain spaces, quotes, '=' or
+// non-printable characters.
+//
+//	Example output:
+//	  time=2024-01-02T03:04:05.006Z level=INFO msg="request done" req.method=GET status=200
+type LogfmtHandler struct {
+	opts   slog.HandlerOptions // slog configuration
+	prefix string              // dotted group prefix of subsequent attributes
+	preBuf string              // formatted attributes from WithAttrs
+	mu     *sync.Mutex         // protects writes to output
+	w      io.Writer           // output destination
+}
+
+// NewLogfmtHandler returns new [LogfmtHandler] instance.
+// opts: optional handler configuration (nil uses defaults)
+// w: output writer (e.g., os.Stderr, os.Stdout)
+func NewLogfmtHandler(w io.Writer, opts *slog.HandlerOptions) *LogfmtHandler {
+	h := &LogfmtHandler{
+		w:  w,
+		mu: new(sync.Mutex),
+	}
+
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo // default to Info level
+	}
+
+	return h
+}
+
+// Enabled checks if the handler should process this log level,
+// implements [slog.Handler] interface.
+func (h *LogfmtHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle writes the record as a single line,
+// implements [slog.Handler] interface.
+func (h *LogfmtHandler) Handle(ctx context.Context, r slog.Record) error {
+	b := builderPool.Get().(*strings.Builder)
+	b.Reset()
+	defer builderPool.Put(b) // return to pool when done
+
+	if !r.Time.IsZero() {
+		appendLogfmtPair(b, slog.TimeKey, r.Time.Format(logfmtTimeFormat), ansiLightGray)
+		b.WriteByte(' ')
+	}
+	appendLogfmtPair(b, slog.LevelKey, r.Level.String(), levelColor(r.Level))
+	b.WriteByte(' ')
+	appendLogfmtPair(b, slog.MessageKey, r.Message, ansiWhite)
+
+	b.WriteString(h.preBuf)
+	r.Attrs(func(a slog.Attr) bool {
+		appendLogfmtAttr(b, h.prefix, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	// write final output with mutex protection
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+// WithAttrs creates a new handler with additional attributes,
+// implements [slog.Handler] interface.
+func (h *LogfmtHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h // no-op for empty attributes
+	}
+
+	b := builderPool.Get().(*strings.Builder)
+	b.Reset()
+	defer builderPool.Put(b)
+
+	b.WriteString(h.preBuf)
+	for _, a := range attrs {
+		appendLogfmtAttr(b, h.prefix, a)
+	}
+
+	h2 := *h
+	h2.preBuf = b.String()
+	return &h2
+}
+
+// WithGroup creates a new handler prefixing subsequent attribute keys
+// with the group name, implements [slog.Handler] interface.
+func (h *LogfmtHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h // no-op for empty group names
+	}
+
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// appendLogfmtAttr writes an attribute as " key=value", groups as their
+// members with the group name added to prefix.
+func appendLogfmtAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return // skip empty attributes
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			appendLogfmtAttr(b, prefix, ga)
+		}
+		return
+	}
+
+	b.WriteByte(' ')
+	appendLogfmtPair(b, prefix+a.Key, logfmtValue(a.Value), ansiDarkGray)
+}
+
+// appendLogfmtPair writes a dimmed "key=" followed by the value in color.
+func appendLogfmtPair(b *strings.Builder, key, value, color string) {
+	if color == ansiDarkGray {
+		b.WriteString(colorize(ansiDarkGray, logfmtQuote(key)+"="+logfmtQuote(value)))
+		return
+	}
+	b.WriteString(colorize(ansiDarkGray, logfmtQuote(key)+"="))
+	b.WriteString(colorize(color, logfmtQuote(value)))
+}
+
+// logfmtValue formats a value on a single line. Errors, URLs and
+// [fmt.Stringer] values use their string form, other complex values are
+// encoded as compact JSON.
+func logfmtValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		// formatted below
+	default:
+		return v.String()
+	}
+
+	switch v := v.Any().(type) {
+	case error:
+		if isNilPointer(v) {
+			return "<nil>"
+		}
+		return v.Error()
+	case []byte:
+		return string(v)
+	case Block:
+		return string(v)
+	case url.URL:
+		return v.String()
+	case fmt.Stringer:
+		if isNilPointer(v) {
+			return "<nil>"
+		}
+		return v.String()
+	}
+
+	data, err := json.Marshal(v.Any())
+	if err != nil {
+		// panic is intentional - invalid values should fail fast
+		panic(fmt.Sprintf("log marshaling error: %v", err))
+	}
+	return string(data)
+}
+
+// logfmtQuote quotes s if it is empty or contains spaces, quotes, '=',
+// non-printable characters or invalid UTF-8.
+func logfmtQuote(s string) string {
+	if s == "" || strings.ContainsFunc(s, func(r rune) bool {
+		return r == '"' || r == '=' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r)
+	}) {
+		return strconv.Quote(s)
+	}
+	return s
+}
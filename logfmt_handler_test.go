@@ -0,0 +1,191 @@
+package conslog_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"os"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/voler88/conslog"
+	"github.com/voler88/conslog/pkg/logparse"
+	"github.com/voler88/conslog/pkg/logtest"
+)
+
+// logfmtEntry converts a parsed record into the map form of slogtest.
+func logfmtEntry(r slog.Record) map[string]any {
+	m := map[string]any{slog.LevelKey: r.Level, slog.MessageKey: r.Message}
+	if !r.Time.IsZero() {
+		m[slog.TimeKey] = r.Time
+	}
+	var toMap func(attrs []slog.Attr) map[string]any
+	toMap = func(attrs []slog.Attr) map[string]any {
+		g := make(map[string]any)
+		for _, a := range attrs {
+			if a.Value.Kind() == slog.KindGroup {
+				g[a.Key] = toMap(a.Value.Group())
+			} else {
+				g[a.Key] = a.Value.Any()
+			}
+		}
+		return g
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Value.Kind() == slog.KindGroup {
+			m[a.Key] = toMap(a.Value.Group())
+		} else {
+			m[a.Key] = a.Value.Any()
+		}
+		return true
+	})
+	return m
+}
+
+// TestLogfmtSlogtest verifies compatibility with slogtest.TestHandler.
+func TestLogfmtSlogtest(t *testing.T) {
+	var buf bytes.Buffer
+	err := slogtest.TestHandler(conslog.NewLogfmtHandler(&buf, nil), func() []map[string]any {
+		var entries []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte{'\n'}) {
+			r, err := logparse.ParseLogfmt([]byte(uncolorize(t, string(line))), logparse.SlogKeys)
+			if err != nil {
+				t.Fatalf("unparsable line %q: %v", line, err)
+			}
+			entries = append(entries, logfmtEntry(r))
+		}
+		return entries
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLogfmtQuoting verifies which keys and values are quoted.
+func TestLogfmtQuoting(t *testing.T) {
+	tests := []struct {
+		name string
+		attr slog.Attr
+		want string
+	}{
+		{"Plain", slog.String("k", "value"), "k=value"},
+		{"Empty", slog.String("k", ""), `k=""`},
+		{"Space", slog.String("k", "two words"), `k="two words"`},
+		{"Quote", slog.String("k", `say "hi"`), `k="say \"hi\""`},
+		{"Equals", slog.String("k", "a=b"), `k="a=b"`},
+		{"Newline", slog.String("k", "a\nb"), `k="a\nb"`},
+		{"Control", slog.String("k", "a\x00b"), `k="a\x00b"`},
+		{"Unicode", slog.String("k", "grüße"), "k=grüße"},
+		{"KeySpace", slog.Int("my key", 1), `"my key"=1`},
+		{"EmptyKey", slog.Int("", 1), `""=1`},
+		{"Block", slog.Any("sql", conslog.Block("SELECT 1\nFROM t")), `sql="SELECT 1\nFROM t"`},
+		{"NilError", slog.Any("err", (*os.PathError)(nil)), "err=<nil>"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := slog.New(conslog.NewLogfmtHandler(&buf, nil))
+			l.LogAttrs(context.Background(), slog.LevelInfo, "m", tc.attr)
+			got := uncolorize(t, buf.String())
+			if want := "level=INFO msg=m " + tc.want + "\n"; got[len(got)-len(want):] != want {
+				t.Errorf("expected suffix %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+// TestLogfmtLevel verifies level filtering and the default level.
+func TestLogfmtLevel(t *testing.T) {
+	h := conslog.NewLogfmtHandler(new(bytes.Buffer), nil)
+	if h.Enabled(context.Background(), slog.LevelDebug) || !h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("expected Info as default level")
+	}
+	h = conslog.NewLogfmtHandler(new(bytes.Buffer), &slog.HandlerOptions{Level: slog.LevelError})
+	if h.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("expected Warn to be disabled")
+	}
+}
+
+// TestLogfmtGoldenOutput compares complete output including colors
+// against testdata/logfmt, set CONSLOG_UPDATE_GOLDEN=1 to rewrite
+// the files.
+func TestLogfmtGoldenOutput(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6_000_000, time.UTC)
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug - 4}
+
+	tests := []struct {
+		name  string
+		setup func(slog.Handler) slog.Handler
+		recs  func() []slog.Record
+	}{
+		{
+			"levels",
+			nil,
+			func() []slog.Record {
+				var recs []slog.Record
+				for _, l := range []slog.Level{
+					slog.LevelDebug - 4, slog.LevelDebug, slog.LevelInfo, slog.LevelInfo + 2,
+					slog.LevelWarn, slog.LevelError, slog.LevelError + 4,
+				} {
+					recs = append(recs, slog.NewRecord(ts, l, "level "+l.String(), 0))
+				}
+				return recs
+			},
+		},
+		{
+			"groups",
+			func(h slog.Handler) slog.Handler {
+				return h.WithAttrs([]slog.Attr{slog.String("app", "api")}).
+					WithGroup("req").
+					WithAttrs([]slog.Attr{slog.String("id", "42")})
+			},
+			func() []slog.Record {
+				r := slog.NewRecord(ts, slog.LevelInfo, "request done", 0)
+				r.AddAttrs(
+					slog.String("method", "GET"),
+					slog.Group("headers", slog.String("accept", "application/json")),
+					slog.Group("empty"),
+					slog.Group("", slog.Int("inlined", 1)),
+				)
+				return []slog.Record{r}
+			},
+		},
+		{
+			"values",
+			nil,
+			func() []slog.Record {
+				u, _ := url.Parse("https://example.com/path?q=1")
+				r := slog.NewRecord(ts, slog.LevelError, "values", 0)
+				r.AddAttrs(
+					slog.Duration("took", 1500*time.Millisecond),
+					slog.Time("at", ts),
+					slog.Any("err", errors.New("connection refused")),
+					slog.Any("url", u),
+					slog.Any("tags", []string{"a", "b"}),
+					slog.Float64("ratio", 0.25),
+					slog.Bool("ok", false),
+				)
+				return []slog.Record{r, slog.NewRecord(time.Time{}, slog.LevelInfo, "no time", 0)}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var h slog.Handler = conslog.NewLogfmtHandler(&buf, opts)
+			if tc.setup != nil {
+				h = tc.setup(h)
+			}
+			for _, r := range tc.recs() {
+				if err := h.Handle(context.Background(), r); err != nil {
+					t.Fatalf("Handle failed: %v", err)
+				}
+			}
+			logtest.GoldenANSI(t, "logfmt/"+tc.name, buf.Bytes())
+		})
+	}
+}
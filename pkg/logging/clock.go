@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/voler88/conslog"
+)
+
+// clockHandler wraps a [slog.Handler] and replaces record timestamps
+// with the time of a [conslog.Clock].
+type clockHandler struct {
+	slog.Handler
+	clock conslog.Clock
+}
+
+// Handle stamps the record with the clock time and passes it on,
+// implements [slog.Handler] interface.
+func (h *clockHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !r.Time.IsZero() {
+		r.Time = h.clock.Now()
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements [slog.Handler] interface.
+func (h *clockHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &clockHandler{h.Handler.WithAttrs(attrs), h.clock}
+}
+
+// WithGroup implements [slog.Handler] interface.
+func (h *clockHandler) WithGroup(name string) slog.Handler {
+	return &clockHandler{h.Handler.WithGroup(name), h.clock}
+}
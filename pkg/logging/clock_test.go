@@ -0,0 +1,45 @@
+package logging_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logging"
+	"github.com/voler88/conslog/pkg/logtest"
+)
+
+// TestWithClock verifies deterministic timestamps for all handler types.
+func TestWithClock(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		handler logging.HandlerType
+		want    []string
+	}{
+		{logging.JSON, []string{`"time":"2024-01-02T03:04:05Z"`, `"time":"2024-01-02T03:04:06Z"`}},
+		{logging.Text, []string{"time=2024-01-02T03:04:05.000Z", "time=2024-01-02T03:04:06.000Z"}},
+		{logging.Console, []string{"[03:04:05.000]", "[03:04:06.000]"}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.handler.String(), func(t *testing.T) {
+			clock := logtest.NewClock(start)
+			clock.SetStep(time.Second)
+
+			var buf bytes.Buffer
+			l := logging.NewLogger(&buf, tc.handler,
+				logging.WithClock(clock), logging.WithStackTrace(logging.LevelError))
+			l.Info("first")
+			l.With("k", "v").Info("second")
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) < 2 {
+				t.Fatalf("expected 2 lines, got: %s", buf.String())
+			}
+			if !strings.Contains(lines[0], tc.want[0]) || !strings.Contains(buf.String(), tc.want[1]) {
+				t.Errorf("expected timestamps %q, got:\n%s", tc.want, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package logging
+
+import (
+	"context"
+	"io"
+)
+
+// ctxKey is the context key for the request scoped [Logger].
+type ctxKey struct{}
+
+// discard is returned by [FromContext] when no logger is stored.
+var discard = NewLogger(io.Discard, JSON)
+
+// NewContext returns a copy of ctx that carries l.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the [Logger] stored in ctx by [NewContext] or
+// [AccessLogMiddleware]. If there is none, it returns a logger that
+// discards all output.
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(ctxKey{}).(Logger); ok {
+		return l
+	}
+	return discard
+}
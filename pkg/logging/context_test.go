@@ -0,0 +1,29 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// TestContext verifies storing and retrieving a [logging.Logger] in a context.
+func TestContext(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON).With("request_id", "abc")
+
+	ctx := logging.NewContext(context.Background(), l)
+	logging.FromContext(ctx).Error("from context")
+	if !strings.Contains(buf.String(), `"request_id":"abc"`) {
+		t.Errorf("expected stored logger to be used, got: %s", buf.String())
+	}
+
+	// missing logger falls back to discarding output
+	fallback := logging.FromContext(context.Background())
+	if fallback == nil {
+		t.Fatal("expected fallback logger, got nil")
+	}
+	fallback.Error("discarded")
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"runtime"
+
+	"github.com/voler88/conslog"
+)
+
+// maxStackDepth limits the number of frames captured for an error.
+const maxStackDepth = 32
+
+// stackError wraps an error with a message, attributes and the call stack
+// captured at the wrap site. It is rendered by [conslog.ConsoleHandler]
+// as an error tree and marshaled into a structured object by JSON handlers.
+type stackError struct {
+	msg   string
+	err   error
+	attrs []slog.Attr
+	stack []uintptr
+}
+
+// WrapError returns an error wrapping err with an optional message and
+// key-value pairs, capturing the call stack of the caller.
+// Returns nil if err is nil.
+func WrapError(err error, msg string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(2, pcs) // skip runtime.Callers and WrapError
+
+	return &stackError{
+		msg:   msg,
+		err:   err,
+		attrs: argsToAttrs(args),
+		stack: pcs[:n],
+	}
+}
+
+// Error implements the error interface.
+func (e *stackError) Error() string {
+	if e.msg == "" {
+		return errorMessage(e.err)
+	}
+	return e.msg + ": " + errorMessage(e.err)
+}
+
+// Unwrap returns the wrapped error.
+func (e *stackError) Unwrap() error {
+	return e.err
+}
+
+// Attrs returns attributes attached to the error,
+// implements [conslog.AttrCarrier] interface.
+func (e *stackError) Attrs() []slog.Attr {
+	return e.attrs
+}
+
+// StackTrace returns program counters of the wrap site,
+// implements [conslog.StackTracer] interface.
+func (e *stackError) StackTrace() []uintptr {
+	return e.stack
+}
+
+// MarshalJSON returns the structured form of [conslog.ErrorValue], so JSON
+// handlers keep attributes and stack, implements [json.Marshaler] interface.
+func (e *stackError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(conslog.ErrorValue(e).Any())
+}
+
+// errorMessage returns the message of err, "<nil>" for nil pointers
+// whose Error method would panic.
+func errorMessage(err error) string {
+	if isNilPointer(err) {
+		return "<nil>"
+	}
+	return err.Error()
+}
+
+// isNilPointer reports whether v holds a nil pointer, so methods
+// with pointer receivers are not called on it.
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// argsToAttrs converts alternating key-value pairs and [slog.Attr] values
+// into attributes using the same rules as [slog.Logger].
+func argsToAttrs(args []any) []slog.Attr {
+	if len(args) == 0 {
+		return nil
+	}
+	return slog.Group("", args...).Value.Group()
+}
@@ -0,0 +1,106 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// TestWrapError verifies messages, unwrapping and nil handling of [logging.WrapError].
+func TestWrapError(t *testing.T) {
+	if logging.WrapError(nil, "msg") != nil {
+		t.Error("WrapError(nil) should return nil")
+	}
+
+	base := errors.New("boom")
+	tt := []struct {
+		name    string
+		msg     string
+		wantMsg string
+	}{
+		{"WithMessage", "load config", "load config: boom"},
+		{"WithoutMessage", "", "boom"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := logging.WrapError(base, tc.msg, "path", "/etc/app")
+			if err.Error() != tc.wantMsg {
+				t.Errorf("expected message %q, got %q", tc.wantMsg, err.Error())
+			}
+			if !errors.Is(err, base) {
+				t.Error("expected wrapped error to match base with errors.Is")
+			}
+		})
+	}
+}
+
+// TestWrapErrorConsole verifies that wrapped errors render attributes and stack in console output.
+func TestWrapErrorConsole(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.Console)
+
+	l.Error("failed", "err", logging.WrapError(errors.New("boom"), "load", "path", "/etc/app"))
+
+	out := buf.String()
+	for _, want := range []string{"load: boom", "/etc/app", "stack:", "TestWrapErrorConsole", "caused by:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got: %s", want, out)
+		}
+	}
+}
+
+// TestStructuredErrors verifies that plain errors are expanded in JSON output
+// only with [logging.WithStructuredErrors].
+func TestStructuredErrors(t *testing.T) {
+	err := fmt.Errorf("load: %w", errors.New("boom"))
+
+	var buf bytes.Buffer
+	logging.NewLogger(&buf, logging.JSON).Error("failed", "err", err)
+	if !strings.Contains(buf.String(), `"err":"load: boom"`) {
+		t.Errorf("expected error message string by default, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	logging.NewLogger(&buf, logging.JSON, logging.WithStructuredErrors()).Error("failed", "err", err)
+	if !strings.Contains(buf.String(), `"err":{"msg":"load: boom","causes":[{"msg":"boom"}]}`) {
+		t.Errorf("expected structured error object, got: %s", buf.String())
+	}
+}
+
+// TestWrapErrorJSON verifies that JSON output contains a structured error object.
+func TestWrapErrorJSON(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+
+	l.Error("failed", "err", logging.WrapError(errors.New("boom"), "load", "path", "/etc/app"))
+
+	var logLine struct {
+		Err struct {
+			Msg    string           `json:"msg"`
+			Attrs  map[string]any   `json:"attrs"`
+			Stack  []map[string]any `json:"stack"`
+			Causes []map[string]any `json:"causes"`
+		} `json:"err"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &logLine); err != nil {
+		t.Fatalf("failed to parse log line: %v", err)
+	}
+
+	if logLine.Err.Msg != "load: boom" {
+		t.Errorf("expected msg 'load: boom', got: %v", logLine.Err.Msg)
+	}
+	if logLine.Err.Attrs["path"] != "/etc/app" {
+		t.Errorf("expected attrs.path '/etc/app', got: %v", logLine.Err.Attrs)
+	}
+	if len(logLine.Err.Stack) == 0 {
+		t.Error("expected non-empty stack")
+	}
+	if len(logLine.Err.Causes) != 1 || logLine.Err.Causes[0]["msg"] != "boom" {
+		t.Errorf("expected single cause 'boom', got: %v", logLine.Err.Causes)
+	}
+}
@@ -0,0 +1,346 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/voler88/conslog/pkg/logquery"
+)
+
+// LoggerKey is the attribute key holding a logger name, e.g. added with
+// l.With(logging.LoggerKey, "db"), matched by [Rule.Logger].
+const LoggerKey = "logger"
+
+// FilterAction is the action applied to records matching a [Rule].
+type FilterAction string
+
+// Supported filter actions.
+const (
+	FilterDrop  FilterAction = "drop"  // discard the record
+	FilterAllow FilterAction = "allow" // log the record even below the logger level
+	FilterLevel FilterAction = "level" // log the record at the rule level
+)
+
+// Rule matches records and applies an action. All set conditions must
+// match, a rule without conditions matches every record.
+type Rule struct {
+	Level    string            `json:"level,omitempty"`     // e.g. "debug", ">=warn" or "<info"
+	Message  string            `json:"message,omitempty"`   // glob matching the whole message
+	Regex    string            `json:"regex,omitempty"`     // regular expression matching the message
+	Logger   string            `json:"logger,omitempty"`    // glob matching the LoggerKey attribute
+	Attrs    map[string]string `json:"attrs,omitempty"`     // globs matching values by dot separated path
+	Expr     string            `json:"expr,omitempty"`      // expression, see package logquery
+	Action   FilterAction      `json:"action"`              // drop, allow or level
+	SetLevel string            `json:"set_level,omitempty"` // new level of the level action
+}
+
+// condition reports whether a record matches part of a rule.
+type condition func(*filterRecord) bool
+
+// compiledRule is a validated [Rule].
+type compiledRule struct {
+	levelOK func(Level) bool // level condition, nil matches any level
+	conds   []condition
+	action  FilterAction
+	level   Level // new level of FilterLevel
+}
+
+// match reports whether the record matches all conditions of the rule.
+func (c *compiledRule) match(fr *filterRecord) bool {
+	if c.levelOK != nil && !c.levelOK(fr.r.Level) {
+		return false
+	}
+	for _, cond := range c.conds {
+		if !cond(fr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter is an ordered set of rules, the first matching rule decides what
+// happens to a record. Records matching no rule pass the logger level as usual.
+type Filter struct {
+	rules []compiledRule
+}
+
+// NewFilter validates rules and returns a [Filter] applying them in order.
+func NewFilter(rules ...Rule) (*Filter, error) {
+	f := &Filter{rules: make([]compiledRule, 0, len(rules))}
+	for i, rule := range rules {
+		c, err := compileRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		f.rules = append(f.rules, c)
+	}
+	return f, nil
+}
+
+// NewExprFilter returns a [Filter] that drops records not matching a
+// [logquery] expression, e.g. `level>=warn && http.status>=500`.
+func NewExprFilter(expr string) (*Filter, error) {
+	e, err := logquery.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{rules: []compiledRule{{
+		conds:  []condition{func(fr *filterRecord) bool { return !e.Match(fr) }},
+		action: FilterDrop,
+	}}}, nil
+}
+
+// LoadFilter reads rules from a JSON array, e.g.
+//
+//	[
+//	  {"message": "health check*", "action": "drop"},
+//	  {"logger": "db", "level": "debug", "action": "allow"},
+//	  {"attrs": {"http.status": "5??"}, "action": "level", "set_level": "error"}
+//	]
+func LoadFilter(r io.Reader) (*Filter, error) {
+	var rules []Rule
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("failed to decode filter rules: %w", err)
+	}
+	return NewFilter(rules...)
+}
+
+// compileRule validates a rule and compiles its conditions.
+func compileRule(rule Rule) (compiledRule, error) {
+	c := compiledRule{action: rule.Action}
+
+	switch rule.Action {
+	case FilterDrop, FilterAllow:
+	case FilterLevel:
+		if err := c.level.UnmarshalText([]byte(rule.SetLevel)); err != nil {
+			return c, fmt.Errorf("invalid set_level %q: %w", rule.SetLevel, err)
+		}
+	default:
+		return c, fmt.Errorf("invalid action %q: must be one of drop, allow, level", rule.Action)
+	}
+
+	if rule.Level != "" {
+		levelOK, err := parseLevelCondition(rule.Level)
+		if err != nil {
+			return c, err
+		}
+		c.levelOK = levelOK
+	}
+	if rule.Message != "" {
+		re := globRegexp(rule.Message)
+		c.conds = append(c.conds, func(fr *filterRecord) bool { return re.MatchString(fr.r.Message) })
+	}
+	if rule.Regex != "" {
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return c, fmt.Errorf("invalid regex %q: %w", rule.Regex, err)
+		}
+		c.conds = append(c.conds, func(fr *filterRecord) bool { return re.MatchString(fr.r.Message) })
+	}
+	if rule.Logger != "" {
+		c.conds = append(c.conds, attrCondition(LoggerKey, rule.Logger))
+	}
+	for path, glob := range rule.Attrs {
+		c.conds = append(c.conds, attrCondition(path, glob))
+	}
+	if rule.Expr != "" {
+		expr, err := logquery.Parse(rule.Expr)
+		if err != nil {
+			return c, fmt.Errorf("invalid expr %q: %w", rule.Expr, err)
+		}
+		c.conds = append(c.conds, func(fr *filterRecord) bool { return expr.Match(fr) })
+	}
+	return c, nil
+}
+
+// attrCondition matches records with an attribute at path whose
+// string value matches glob.
+func attrCondition(path, glob string) condition {
+	re := globRegexp(glob)
+	return func(fr *filterRecord) bool {
+		v, ok := fr.Attr(path)
+		return ok && re.MatchString(v.String())
+	}
+}
+
+// globRegexp converts a glob with * and ? wildcards into an anchored regexp.
+func globRegexp(glob string) *regexp.Regexp {
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.NewReplacer(`\*`, `.*`, `\?`, `.`).Replace(pattern)
+	return regexp.MustCompile(`^(?s:` + pattern + `)$`)
+}
+
+// parseLevelCondition parses a level with an optional comparison operator,
+// e.g. "warn", ">=info" or "<error". Without an operator levels must be equal.
+func parseLevelCondition(s string) (func(Level) bool, error) {
+	var op string
+	for _, prefix := range []string{">=", "<=", "==", "!=", ">", "<", "="} {
+		if strings.HasPrefix(s, prefix) {
+			op, s = prefix, s[len(prefix):]
+			break
+		}
+	}
+
+	var want Level
+	if err := want.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return nil, fmt.Errorf("invalid level condition %q: %w", s, err)
+	}
+	switch op {
+	case ">=":
+		return func(l Level) bool { return l >= want }, nil
+	case "<=":
+		return func(l Level) bool { return l <= want }, nil
+	case ">":
+		return func(l Level) bool { return l > want }, nil
+	case "<":
+		return func(l Level) bool { return l < want }, nil
+	case "!=":
+		return func(l Level) bool { return l != want }, nil
+	default:
+		return func(l Level) bool { return l == want }, nil
+	}
+}
+
+// mayOverride reports whether a rule may allow or raise a record at level
+// that the wrapped handler would not log.
+func (f *Filter) mayOverride(level Level) bool {
+	for _, c := range f.rules {
+		if c.action != FilterDrop && (c.levelOK == nil || c.levelOK(level)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler returns a [slog.Handler] applying the filter before next, e.g.
+// a [conslog.ConsoleHandler] or the JSON and Text handlers of slog.
+func (f *Filter) Handler(next slog.Handler) slog.Handler {
+	return &filterHandler{next: next, filter: f}
+}
+
+// flatAttr is an attribute with the dot separated path of its groups.
+type flatAttr struct {
+	path  string
+	value slog.Value
+}
+
+// flattenAttrs appends attributes with resolved values and paths below prefix.
+func flattenAttrs(dst []flatAttr, prefix string, attrs []slog.Attr) []flatAttr {
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		if v.Kind() == slog.KindGroup {
+			p := prefix
+			if a.Key != "" {
+				p += a.Key + "."
+			}
+			dst = flattenAttrs(dst, p, v.Group())
+			continue
+		}
+		dst = append(dst, flatAttr{prefix + a.Key, v})
+	}
+	return dst
+}
+
+// filterRecord gives rules access to a record and the attributes bound
+// to its handler, record attributes are flattened on first access.
+// Implements [logquery.Fields] interface.
+type filterRecord struct {
+	r      *slog.Record
+	bound  []flatAttr
+	prefix string
+	attrs  []flatAttr
+}
+
+// Level returns the record level.
+func (fr *filterRecord) Level() Level {
+	return fr.r.Level
+}
+
+// Message returns the record message.
+func (fr *filterRecord) Message() string {
+	return fr.r.Message
+}
+
+// Attr returns the value of the last attribute at path.
+func (fr *filterRecord) Attr(path string) (slog.Value, bool) {
+	if fr.attrs == nil {
+		fr.attrs = append(make([]flatAttr, 0, len(fr.bound)+fr.r.NumAttrs()), fr.bound...)
+		var recAttrs []slog.Attr
+		fr.r.Attrs(func(a slog.Attr) bool {
+			recAttrs = append(recAttrs, a)
+			return true
+		})
+		fr.attrs = flattenAttrs(fr.attrs, fr.prefix, recAttrs)
+	}
+	for i := len(fr.attrs) - 1; i >= 0; i-- {
+		if fr.attrs[i].path == path {
+			return fr.attrs[i].value, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+// filterHandler applies a [Filter] and passes remaining records on.
+type filterHandler struct {
+	next   slog.Handler
+	filter *Filter
+	bound  []flatAttr // attributes added with WithAttrs
+	prefix string     // dot separated groups opened with WithGroup
+}
+
+// Enabled reports whether the wrapped handler or a rule may log level,
+// implements [slog.Handler] interface.
+func (h *filterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level) || h.filter.mayOverride(level)
+}
+
+// Handle applies the first matching rule, implements [slog.Handler] interface.
+func (h *filterHandler) Handle(ctx context.Context, r slog.Record) error {
+	fr := &filterRecord{r: &r, bound: h.bound, prefix: h.prefix}
+	for i := range h.filter.rules {
+		rule := &h.filter.rules[i]
+		if !rule.match(fr) {
+			continue
+		}
+		switch rule.action {
+		case FilterDrop:
+			return nil
+		case FilterAllow:
+			return h.next.Handle(ctx, r)
+		case FilterLevel:
+			r.Level = rule.level
+		}
+		break
+	}
+
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements [slog.Handler] interface.
+func (h *filterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	h2.bound = flattenAttrs(h.bound[:len(h.bound):len(h.bound)], h.prefix, attrs)
+	return &h2
+}
+
+// WithGroup implements [slog.Handler] interface.
+func (h *filterHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
@@ -0,0 +1,140 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+	"github.com/voler88/conslog/pkg/logtest"
+)
+
+// filterLogger returns an Info level logger recording records that pass rules.
+func filterLogger(t *testing.T, rules ...logging.Rule) (logging.Logger, *logtest.Handler) {
+	t.Helper()
+	f, err := logging.NewFilter(rules...)
+	if err != nil {
+		t.Fatalf("NewFilter failed: %v", err)
+	}
+	lvl := new(slog.LevelVar)
+	h := logtest.NewHandler(lvl)
+	return logging.NewFromHandler(f.Handler(h), lvl), h
+}
+
+// TestFilterActions verifies drop, allow and level change rules.
+func TestFilterActions(t *testing.T) {
+	l, h := filterLogger(t,
+		logging.Rule{Message: "health check*", Action: logging.FilterDrop},
+		logging.Rule{Logger: "db", Level: "<info", Action: logging.FilterAllow},
+		logging.Rule{Attrs: map[string]string{"http.status": "5??"}, Action: logging.FilterLevel, SetLevel: "error"},
+		logging.Rule{Regex: `cache (miss|evict)`, Action: logging.FilterLevel, SetLevel: "debug"},
+	)
+
+	l.Info("health check ok")
+	l.Debug("debug of other logger")
+	l.With(logging.LoggerKey, "db").Debug("query plan")
+	l.WithGroup("http").With("status", 503).Info("request")
+	l.Info("request", slog.Group("http", "status", 200))
+	l.Warn("cache miss")
+	l.Info("server started")
+
+	var got []string
+	for _, r := range h.Records() {
+		got = append(got, r.Level.String()+" "+r.Message)
+	}
+	want := []string{"DEBUG query plan", "ERROR request", "INFO request", "INFO server started"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+// TestFilterFirstMatchWins verifies rule order.
+func TestFilterFirstMatchWins(t *testing.T) {
+	l, h := filterLogger(t,
+		logging.Rule{Level: ">=error", Action: logging.FilterAllow},
+		logging.Rule{Message: "*", Action: logging.FilterDrop},
+	)
+	l.Warn("dropped")
+	l.Error("kept")
+
+	if h.Count() != 1 || !h.Has(logtest.Message("kept")) {
+		t.Errorf("expected only the error record, got %+v", h.Records())
+	}
+}
+
+// TestLoadFilter verifies loading rules from JSON and validation errors.
+func TestLoadFilter(t *testing.T) {
+	config := `[
+		{"message": "tick", "action": "drop"},
+		{"level": "warn", "attrs": {"retry": "true"}, "action": "level", "set_level": "info"}
+	]`
+	f, err := logging.LoadFilter(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("LoadFilter failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON, logging.WithFilter(f))
+	l.Info("tick")
+	l.Warn("connection reset", "retry", true)
+
+	var line struct{ Level, Msg string }
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected a single record: %v: %s", err, buf.String())
+	}
+	if line.Level != "INFO" || line.Msg != "connection reset" {
+		t.Errorf("expected lowered record, got %+v", line)
+	}
+
+	for name, config := range map[string]string{
+		"UnknownField": `[{"msg": "x", "action": "drop"}]`,
+		"Action":       `[{"action": "ignore"}]`,
+		"SetLevel":     `[{"action": "level"}]`,
+		"Level":        `[{"level": ">=loud", "action": "drop"}]`,
+		"Regex":        `[{"regex": "(", "action": "drop"}]`,
+	} {
+		if _, err := logging.LoadFilter(strings.NewReader(config)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// TestExprFilter verifies expression filters and rules with bound attributes.
+func TestExprFilter(t *testing.T) {
+	f, err := logging.NewExprFilter(`level>=warn && http.status>=500 && msg~"timeout"`)
+	if err != nil {
+		t.Fatalf("NewExprFilter failed: %v", err)
+	}
+	lvl := new(slog.LevelVar)
+	h := logtest.NewHandler(lvl)
+	l := logging.NewFromHandler(f.Handler(h), lvl).WithGroup("http")
+
+	l.With("status", 504).Warn("upstream timeout")
+	l.With("status", 404).Warn("upstream timeout")
+	l.Error("db timeout", "status", 500)
+	l.Info("upstream timeout", "status", 503)
+
+	var got []string
+	for _, r := range h.Records() {
+		got = append(got, r.Message)
+	}
+	if strings.Join(got, ",") != "upstream timeout,db timeout" {
+		t.Errorf("expected matching records only, got %q", got)
+	}
+
+	if _, err := logging.NewExprFilter("level>="); err == nil {
+		t.Error("expected parse error")
+	}
+	if _, err := logging.NewFilter(logging.Rule{Expr: "a &&", Action: logging.FilterDrop}); err == nil {
+		t.Error("expected rule error for invalid expression")
+	}
+
+	l2, h2 := filterLogger(t, logging.Rule{Expr: `user.admin && level<info`, Action: logging.FilterAllow})
+	l2.WithGroup("user").With("admin", true).Debug("admin debug")
+	l2.WithGroup("user").With("admin", false).Debug("user debug")
+	if h2.Count() != 1 || !h2.Has(logtest.Message("admin debug")) {
+		t.Errorf("expected only the admin record, got %+v", h2.Records())
+	}
+}
@@ -0,0 +1,248 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ecsVersion is the Elastic Common Schema version written by [ECS] handlers.
+const ecsVersion = "1.6.0"
+
+// newECSHandler returns a handler writing Elastic Common Schema JSON.
+// Attributes are top-level fields, errors map to the error field set.
+func newECSHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	return newSchemaHandler(w, opts, jsonLayout(func(r schemaRecord) any {
+		m := attrsToJSON(r.Attrs)
+		if !r.Time.IsZero() {
+			m["@timestamp"] = r.Time.UTC().Format(time.RFC3339Nano)
+		}
+		setPath(m, strings.ToLower(r.Level.String()), "log", "level")
+		m["message"] = r.Message
+		setPath(m, ecsVersion, "ecs", "version")
+		if r.Source != nil {
+			setPath(m, r.Source.File, "log", "origin", "file", "name")
+			setPath(m, r.Source.Line, "log", "origin", "file", "line")
+			setPath(m, r.Source.Function, "log", "origin", "function")
+		}
+		if r.Err != nil {
+			setPath(m, errorMessage(r.Err), "error", "message")
+			setPath(m, fmt.Sprintf("%T", r.Err), "error", "type")
+			if stack := errorStackText(r.Err); stack != "" {
+				setPath(m, stack, "error", "stack_trace")
+			}
+		}
+		return m
+	}))
+}
+
+// gelfFieldRe matches characters not allowed in GELF field names.
+var gelfFieldRe = regexp.MustCompile(`[^\w.\-]`)
+
+// newGELFHandler returns a handler writing Graylog Extended Log Format 1.1
+// JSON. Attributes become additional fields named after their group path
+// joined by underscores, with string or number values.
+func newGELFHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+
+	return newSchemaHandler(w, opts, jsonLayout(func(r schemaRecord) any {
+		m := map[string]any{
+			"version":       "1.1",
+			"host":          host,
+			"short_message": r.Message,
+			"level":         syslogSeverity(r.Level),
+			"_level_name":   r.Level.String(),
+		}
+		if !r.Time.IsZero() {
+			m["timestamp"] = float64(r.Time.UnixMilli()) / 1e3
+		}
+		for _, a := range flattenAttrs(nil, "", r.Attrs) {
+			name := "_" + gelfFieldRe.ReplaceAllString(strings.ReplaceAll(a.path, ".", "_"), "_")
+			if name == "_id" {
+				name = "__id" // reserved by GELF
+			}
+			switch a.value.Kind() {
+			case slog.KindInt64, slog.KindUint64, slog.KindFloat64:
+				m[name] = a.value.Any()
+			default:
+				m[name] = scalarString(a.value)
+			}
+		}
+		if r.Source != nil {
+			m["_file"] = r.Source.File
+			m["_line"] = r.Source.Line
+			m["_function"] = r.Source.Function
+		}
+		if r.Err != nil {
+			m["_error"] = errorMessage(r.Err)
+			if stack := errorStackText(r.Err); stack != "" {
+				m["full_message"] = r.Message + "\n" + errorMessage(r.Err) + "\n\n" + stack
+			}
+		}
+		return m
+	}))
+}
+
+// otlpValue is an AnyValue of the OpenTelemetry protocol JSON encoding.
+type otlpValue map[string]any
+
+// otlpAttr is a KeyValue of the OpenTelemetry protocol JSON encoding.
+type otlpAttr struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+// newOTLPHandler returns a handler writing log records of the OpenTelemetry
+// log data model in the OTLP JSON encoding, one LogRecord per line.
+// Attributes are flattened to dotted keys as in semantic conventions.
+func newOTLPHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	return newSchemaHandler(w, opts, jsonLayout(func(r schemaRecord) any {
+		var attrs []otlpAttr
+		add := func(key string, v otlpValue) {
+			attrs = append(attrs, otlpAttr{key, v})
+		}
+		for _, a := range flattenAttrs(nil, "", r.Attrs) {
+			add(a.path, otlpAnyValue(a.value))
+		}
+		if r.Source != nil {
+			add("code.filepath", otlpValue{"stringValue": r.Source.File})
+			add("code.lineno", otlpValue{"intValue": strconv.Itoa(r.Source.Line)})
+			add("code.function", otlpValue{"stringValue": r.Source.Function})
+		}
+		if r.Err != nil {
+			add("exception.message", otlpValue{"stringValue": errorMessage(r.Err)})
+			add("exception.type", otlpValue{"stringValue": fmt.Sprintf("%T", r.Err)})
+			if stack := errorStackText(r.Err); stack != "" {
+				add("exception.stacktrace", otlpValue{"stringValue": stack})
+			}
+		}
+
+		m := map[string]any{
+			"severityNumber": otlpSeverity(r.Level),
+			"severityText":   r.Level.String(),
+			"body":           otlpValue{"stringValue": r.Message},
+		}
+		if !r.Time.IsZero() {
+			m["timeUnixNano"] = strconv.FormatInt(r.Time.UnixNano(), 10)
+		}
+		if len(attrs) > 0 {
+			m["attributes"] = attrs
+		}
+		return m
+	}))
+}
+
+// otlpAnyValue converts a non-group value, 64-bit integers are encoded as
+// strings and complex values as JSON strings.
+func otlpAnyValue(v slog.Value) otlpValue {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return otlpValue{"intValue": strconv.FormatInt(v.Int64(), 10)}
+	case slog.KindUint64:
+		return otlpValue{"intValue": strconv.FormatUint(v.Uint64(), 10)}
+	case slog.KindFloat64:
+		return otlpValue{"doubleValue": v.Float64()}
+	case slog.KindBool:
+		return otlpValue{"boolValue": v.Bool()}
+	case slog.KindAny:
+		if b, ok := v.Any().([]byte); ok {
+			return otlpValue{"bytesValue": b} // base64 by encoding/json
+		}
+	}
+	return otlpValue{"stringValue": scalarString(v)}
+}
+
+// otlpSeverity maps a level to an OpenTelemetry severity number, the
+// mapping of the OpenTelemetry slog bridge: Debug is 5, Info 9, Warn 13
+// and Error 17.
+func otlpSeverity(l slog.Level) int {
+	return min(max(int(l)+9, 1), 24)
+}
+
+// gcpSourceLocationKey is the source location field of Cloud Logging.
+const gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+
+// gcpErrorEventType marks records for Error Reporting.
+const gcpErrorEventType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+
+// newGCPHandler returns a handler writing Google Cloud Logging structured
+// JSON. Attributes become fields of the JSON payload, errors with a stack
+// trace are reported to Error Reporting.
+func newGCPHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	return newSchemaHandler(w, opts, jsonLayout(func(r schemaRecord) any {
+		m := attrsToJSON(r.Attrs)
+		if !r.Time.IsZero() {
+			m["time"] = r.Time.Format(time.RFC3339Nano)
+		}
+		m["severity"] = gcpSeverity(r.Level)
+		m["message"] = r.Message
+		if r.Source != nil {
+			m[gcpSourceLocationKey] = map[string]any{
+				"file":     r.Source.File,
+				"line":     strconv.Itoa(r.Source.Line),
+				"function": r.Source.Function,
+			}
+		}
+		if r.Err != nil {
+			m["error"] = errorMessage(r.Err)
+			if stack := errorStackText(r.Err); stack != "" {
+				m["@type"] = gcpErrorEventType
+				m["stack_trace"] = r.Message + ": " + errorMessage(r.Err) + "\n\ngoroutine 1 [running]:\n" + stack
+			}
+		}
+		return m
+	}))
+}
+
+// gcpSeverity maps a level to a Cloud Logging severity.
+func gcpSeverity(l slog.Level) string {
+	switch {
+	case l < slog.LevelInfo:
+		return "DEBUG"
+	case l == slog.LevelInfo:
+		return "INFO"
+	case l < slog.LevelWarn:
+		return "NOTICE"
+	case l < slog.LevelError:
+		return "WARNING"
+	case l < slog.LevelError+4:
+		return "ERROR"
+	case l < slog.LevelError+8:
+		return "CRITICAL"
+	case l < slog.LevelError+12:
+		return "ALERT"
+	default:
+		return "EMERGENCY"
+	}
+}
+
+// syslogSeverity maps a level to a syslog severity of RFC 5424,
+// levels between Info and Warn are notices.
+func syslogSeverity(l slog.Level) int {
+	switch {
+	case l < slog.LevelInfo:
+		return 7 // debug
+	case l == slog.LevelInfo:
+		return 6 // informational
+	case l < slog.LevelWarn:
+		return 5 // notice
+	case l < slog.LevelError:
+		return 4 // warning
+	case l < slog.LevelError+4:
+		return 3 // error
+	case l < slog.LevelError+8:
+		return 2 // critical
+	case l < slog.LevelError+12:
+		return 1 // alert
+	default:
+		return 0 // emergency
+	}
+}
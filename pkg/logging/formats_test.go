@@ -0,0 +1,195 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// logFormat logs a warning with grouped attributes and an error through
+// a logger of the handler type and returns the decoded JSON line.
+func logFormat(t *testing.T, handler logging.HandlerType, options ...logging.Option) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, handler, options...)
+	err := logging.WrapError(errors.New("refused"), "dial")
+	l.With("app", "api").WithGroup("req").Warn("slow request", "id", 7, "took", "2s", "err", err)
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	return m
+}
+
+// field returns the value at a dotted path of nested JSON objects.
+func field(m map[string]any, path string) any {
+	var v any = m
+	for key := range strings.SplitSeq(path, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+// TestFormats verifies the field mapping of the schema handler types.
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler logging.HandlerType
+		want    map[string]any // expected values by dotted path
+		has     []string       // fields that must be present
+	}{
+		{
+			"ECS", logging.ECS,
+			map[string]any{
+				"message":       "slow request",
+				"log.level":     "warn",
+				"ecs.version":   "1.6.0",
+				"app":           "api",
+				"req.id":        7.0,
+				"req.took":      "2s",
+				"error.message": "dial: refused",
+				"error.type":    "*logging.stackError",
+			},
+			[]string{"@timestamp", "error.stack_trace"},
+		},
+		{
+			"GELF", logging.GELF,
+			map[string]any{
+				"version":       "1.1",
+				"short_message": "slow request",
+				"level":         4.0,
+				"_app":          "api",
+				"_req_id":       7.0,
+				"_req_took":     "2s",
+				"_error":        "dial: refused",
+			},
+			[]string{"host", "timestamp", "full_message"},
+		},
+		{
+			"GCP", logging.GCP,
+			map[string]any{
+				"message":  "slow request",
+				"severity": "WARNING",
+				"app":      "api",
+				"req.id":   7.0,
+				"error":    "dial: refused",
+				"@type":    "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent",
+			},
+			[]string{"time", "stack_trace"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if !tc.handler.IsValid() {
+				t.Errorf("expected %q to be valid", tc.handler)
+			}
+			m := logFormat(t, tc.handler)
+			for path, want := range tc.want {
+				if got := field(m, path); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: expected %v, got %v", path, want, got)
+				}
+			}
+			for _, path := range tc.has {
+				if field(m, path) == nil {
+					t.Errorf("expected field %s in %v", path, m)
+				}
+			}
+		})
+	}
+}
+
+// TestFormatsNilError verifies that typed nil errors and stringers are
+// written as "<nil>" instead of panicking.
+func TestFormatsNilError(t *testing.T) {
+	for _, handler := range []logging.HandlerType{logging.ECS, logging.GELF, logging.OTLP, logging.GCP} {
+		t.Run(string(handler), func(t *testing.T) {
+			var buf bytes.Buffer
+			l := logging.NewLogger(&buf, handler)
+			l.Error("failed", "err", (*os.PathError)(nil), "url", (*url.URL)(nil))
+
+			if !json.Valid(buf.Bytes()) || !strings.Contains(buf.String(), `\u003cnil\u003e`) {
+				t.Errorf("expected <nil> for typed nil values, got: %s", buf.String())
+			}
+		})
+	}
+}
+
+// TestFormatOTLP verifies the OTLP JSON encoding of log records.
+func TestFormatOTLP(t *testing.T) {
+	m := logFormat(t, logging.OTLP)
+	if m["severityNumber"] != 13.0 || m["severityText"] != "WARN" {
+		t.Errorf("unexpected severity: %v %v", m["severityNumber"], m["severityText"])
+	}
+	if field(m, "body.stringValue") != "slow request" {
+		t.Errorf("unexpected body: %v", m["body"])
+	}
+	if _, ok := m["timeUnixNano"].(string); !ok {
+		t.Errorf("expected time as string, got %v", m["timeUnixNano"])
+	}
+
+	attrs := make(map[string]any)
+	list, _ := m["attributes"].([]any)
+	for _, a := range list {
+		kv := a.(map[string]any)
+		attrs[kv["key"].(string)] = kv["value"]
+	}
+	want := map[string]any{
+		"app":               map[string]any{"stringValue": "api"},
+		"req.id":            map[string]any{"intValue": "7"},
+		"exception.message": map[string]any{"stringValue": "dial: refused"},
+		"exception.type":    map[string]any{"stringValue": "*logging.stackError"},
+	}
+	for key, v := range want {
+		if !reflect.DeepEqual(attrs[key], v) {
+			t.Errorf("%s: expected %v, got %v", key, v, attrs[key])
+		}
+	}
+	if _, ok := attrs["exception.stacktrace"]; !ok {
+		t.Errorf("expected exception stack trace in %v", attrs)
+	}
+}
+
+// TestWithSource verifies source locations in schema and JSON output.
+func TestWithSource(t *testing.T) {
+	tests := []struct {
+		handler logging.HandlerType
+		path    string
+	}{
+		{logging.ECS, "log.origin.file.name"},
+		{logging.GELF, "_file"},
+		{logging.GCP, "logging.googleapis.com/sourceLocation"},
+		{logging.JSON, "source.file"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.handler.String(), func(t *testing.T) {
+			m := logFormat(t, tc.handler)
+			if field(m, tc.path) != nil {
+				t.Errorf("expected no source without option, got %v", m)
+			}
+
+			m = logFormat(t, tc.handler, logging.WithSource())
+			var got any
+			if tc.handler == logging.GCP {
+				loc, _ := m[tc.path].(map[string]any)
+				got = loc["file"]
+			} else {
+				got = field(m, tc.path)
+			}
+			if file, _ := got.(string); !strings.HasSuffix(file, "formats_test.go") {
+				t.Errorf("expected source file, got %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package logging
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// DefaultJournalSocket is the socket of the journald native protocol.
+const DefaultJournalSocket = "/run/systemd/journal/socket"
+
+// NewJournalHandler returns a handler encoding records as entries of the
+// journald native protocol, one Write per entry, e.g. to a [JournalWriter].
+// Entries have MESSAGE, PRIORITY with the syslog severity of the level,
+// SYSLOG_IDENTIFIER with the program name, CODE_FILE, CODE_LINE and
+// CODE_FUNC of the log call, and attributes as upper-cased fields with
+// group names joined by underscores, e.g. REQ_ID for req.id. Attributes
+// named like these fields are prefixed with X_, e.g. X_MESSAGE.
+func NewJournalHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	var o slog.HandlerOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.AddSource = true // journalctl shows code locations of entries
+
+	identifier := filepath.Base(os.Args[0])
+	return newSchemaHandler(w, &o, func(r schemaRecord) ([]byte, error) {
+		var b []byte
+		b = appendJournalField(b, "MESSAGE", r.Message)
+		b = appendJournalField(b, "PRIORITY", strconv.Itoa(syslogSeverity(r.Level)))
+		b = appendJournalField(b, "SYSLOG_IDENTIFIER", identifier)
+		if r.Source != nil {
+			b = appendJournalField(b, "CODE_FILE", r.Source.File)
+			b = appendJournalField(b, "CODE_LINE", strconv.Itoa(r.Source.Line))
+			b = appendJournalField(b, "CODE_FUNC", r.Source.Function)
+		}
+		if r.Err != nil {
+			b = appendJournalField(b, "ERROR", errorMessage(r.Err))
+		}
+		for _, a := range flattenAttrs(nil, "", r.Attrs) {
+			b = appendJournalField(b, journalFieldName(a.path), scalarString(a.value))
+		}
+		return b, nil
+	})
+}
+
+// appendJournalField appends a field in the native protocol, values with
+// newlines in the binary form with a little-endian 64-bit length.
+func appendJournalField(b []byte, name, value string) []byte {
+	if !strings.Contains(value, "\n") {
+		return append(append(append(b, name...), '='), value+"\n"...)
+	}
+	b = append(append(b, name...), '\n')
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	return append(b, value+"\n"...)
+}
+
+// journalHandlerFields are the fields set by the journal handler itself.
+var journalHandlerFields = map[string]bool{
+	"MESSAGE": true, "PRIORITY": true, "SYSLOG_IDENTIFIER": true, "ERROR": true,
+	"CODE_FILE": true, "CODE_LINE": true, "CODE_FUNC": true,
+}
+
+// journalFieldName converts an attribute path into a journal field name of
+// upper-case letters, digits and underscores, at most 64 characters,
+// not starting with an underscore or digit. Names of fields set by the
+// handler get an X_ prefix, e.g. X_PRIORITY for a priority attribute.
+func journalFieldName(path string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, path)
+	name = strings.TrimLeft(name, "_") // leading underscores are trusted fields
+	if name == "" || name[0] >= '0' && name[0] <= '9' || journalHandlerFields[name] {
+		name = "X_" + name
+	}
+	return name[:min(len(name), 64)]
+}
+
+// JournalWriter sends journald entries, one entry per Write. Entries too
+// large for a datagram are passed as a sealed memory file descriptor.
+// A failed write reconnects and is retried once, e.g. after journald
+// restarted. It is safe for concurrent use.
+type JournalWriter struct {
+	path string
+
+	mu     sync.Mutex
+	conn   *net.UnixConn // nil after a failed reconnect
+	closed bool
+}
+
+// DialJournal connects to the journald socket at path, [DefaultJournalSocket]
+// if empty.
+func DialJournal(path string) (*JournalWriter, error) {
+	if path == "" {
+		path = DefaultJournalSocket
+	}
+	w := &JournalWriter{path: path}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// connect opens a connection, closing the previous one.
+func (w *JournalWriter) connect() error {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: w.path, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// Write sends p as one entry, implements [io.Writer] interface.
+func (w *JournalWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	var err error
+	if w.conn != nil {
+		err = w.send(p)
+	}
+	if w.conn == nil || err != nil && !errors.Is(err, errJournalTooLarge) {
+		if err = w.connect(); err == nil {
+			err = w.send(p)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// errJournalTooLarge is returned for entries exceeding the datagram size
+// where file descriptors cannot be passed.
+var errJournalTooLarge = errors.New("journal entry too large for a datagram")
+
+// send writes an entry as a datagram, or as a file descriptor if too large.
+func (w *JournalWriter) send(p []byte) error {
+	_, err := w.conn.Write(p)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return sendJournalFile(w.conn, p)
+	}
+	return err
+}
+
+// Close closes the connection, later writes return [os.ErrClosed].
+// Implements [io.Closer] interface.
+func (w *JournalWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
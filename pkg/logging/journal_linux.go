@@ -0,0 +1,95 @@
+//go:build linux
+
+package logging
+
+import (
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// memfdCreateTrap holds the memfd_create system call number by architecture,
+// the syscall package does not define it for all of them.
+var memfdCreateTrap = map[string]uintptr{
+	"386": 356, "amd64": 319, "arm": 385, "arm64": 279, "loong64": 279,
+	"ppc64": 360, "ppc64le": 360, "riscv64": 279, "s390x": 350,
+}
+
+// Flags and seals of memory file descriptors.
+const (
+	mfdCloexec      = 0x1
+	mfdAllowSealing = 0x2
+	fAddSeals       = 1033
+	sealAll         = 0x1 | 0x2 | 0x4 | 0x8 // seal, shrink, grow, write
+)
+
+// sendJournalFile passes an entry to journald as a file descriptor, a sealed
+// memfd or an unlinked file in /dev/shm where memfd is unavailable.
+func sendJournalFile(conn *net.UnixConn, p []byte) error {
+	f, err := memfd(p)
+	if err != nil {
+		if f, err = shmFile(p); err != nil {
+			return err
+		}
+	}
+	defer f.Close()
+
+	// WriteMsgUnix refuses connected datagram sockets, send on the raw socket
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	rights := syscall.UnixRights(int(f.Fd()))
+	var sendErr error
+	err = raw.Write(func(fd uintptr) bool {
+		sendErr = syscall.Sendmsg(int(fd), nil, rights, nil, 0)
+		return sendErr != syscall.EAGAIN
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// memfd returns a sealed memory file holding p.
+func memfd(p []byte) (*os.File, error) {
+	trap, ok := memfdCreateTrap[runtime.GOARCH]
+	if !ok {
+		return nil, syscall.ENOSYS
+	}
+	name, err := syscall.BytePtrFromString("journal")
+	if err != nil {
+		return nil, err
+	}
+	fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(name)), mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	f := os.NewFile(fd, "journal")
+	if _, err := f.Write(p); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, fd, fAddSeals, sealAll); errno != 0 {
+		f.Close()
+		return nil, errno
+	}
+	return f, nil
+}
+
+// shmFile returns an unlinked temporary file in /dev/shm holding p.
+func shmFile(p []byte) (*os.File, error) {
+	f, err := os.CreateTemp("/dev/shm", "journal-")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(f.Name())
+	if _, err := f.Write(p); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
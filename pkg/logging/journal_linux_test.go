@@ -0,0 +1,70 @@
+//go:build linux
+
+package logging_test
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// TestJournalWriterLargeEntry verifies that entries exceeding the datagram
+// size are passed as a file descriptor.
+func TestJournalWriterLargeEntry(t *testing.T) {
+	dir, err := os.MkdirTemp("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "socket")
+	sock, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sock.Close()
+
+	w, err := logging.DialJournal(path)
+	if err != nil {
+		t.Fatalf("DialJournal failed: %v", err)
+	}
+	defer w.Close()
+
+	entry := append([]byte("MESSAGE="), bytes.Repeat([]byte("x"), 4<<20)...)
+	entry = append(entry, '\n')
+	if _, err := w.Write(entry); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	oob := make([]byte, syscall.CmsgSpace(4))
+	sock.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, oobn, _, _, err := sock.ReadMsgUnix(make([]byte, 16), oob)
+	if err != nil {
+		t.Fatalf("no entry received: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected empty datagram with file descriptor, got %d bytes", n)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("expected control message, got %v: %v", msgs, err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("expected file descriptor, got %v: %v", fds, err)
+	}
+
+	f := os.NewFile(uintptr(fds[0]), "entry")
+	defer f.Close()
+	got := make([]byte, len(entry)+1)
+	n, _ = f.ReadAt(got, 0)
+	if !bytes.Equal(got[:n], entry) {
+		t.Errorf("file holds %d bytes, expected the %d byte entry", n, len(entry))
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package logging
+
+import "net"
+
+// sendJournalFile reports large entries as unsupported, journald only
+// runs on Linux.
+func sendJournalFile(_ *net.UnixConn, _ []byte) error {
+	return errJournalTooLarge
+}
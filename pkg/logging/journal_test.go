@@ -0,0 +1,217 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// decodeJournal parses an entry of the journald native protocol.
+func decodeJournal(t *testing.T, b []byte) map[string]string {
+	t.Helper()
+	fields := make(map[string]string)
+	for len(b) > 0 {
+		i := bytes.IndexAny(b, "=\n")
+		if i < 0 {
+			t.Fatalf("truncated field %q", b)
+		}
+		name := string(b[:i])
+		if b[i] == '=' {
+			end := i + bytes.IndexByte(b[i:], '\n')
+			fields[name] = string(b[i+1 : end])
+			b = b[end+1:]
+			continue
+		}
+		n := int(binary.LittleEndian.Uint64(b[i+1 : i+9]))
+		fields[name] = string(b[i+9 : i+9+n])
+		if b[i+9+n] != '\n' {
+			t.Fatalf("missing newline after binary field %s", name)
+		}
+		b = b[i+9+n+1:]
+	}
+	return fields
+}
+
+// TestJournalHandler verifies the fields of journal entries.
+func TestJournalHandler(t *testing.T) {
+	var w messageWriter
+	l := slog.New(logging.NewJournalHandler(&w, nil))
+	l.With("app", "api").WithGroup("req").Warn("slow request",
+		"id", 7,
+		"body", "line 1\nline 2",
+		"err", errors.New("refused"),
+		"2fa", true,
+		"_hidden", "x",
+	)
+
+	if len(w.msgs) != 1 {
+		t.Fatalf("expected one entry, got %d", len(w.msgs))
+	}
+	fields := decodeJournal(t, []byte(w.msgs[0]))
+	want := map[string]string{
+		"MESSAGE":           "slow request",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": filepath.Base(os.Args[0]),
+		"APP":               "api",
+		"REQ_ID":            "7",
+		"REQ_BODY":          "line 1\nline 2",
+		"ERROR":             "refused",
+		"REQ_2FA":           "true",
+		"REQ__HIDDEN":       "x",
+	}
+	for name, v := range want {
+		if fields[name] != v {
+			t.Errorf("%s: expected %q, got %q", name, v, fields[name])
+		}
+	}
+	if !strings.HasSuffix(fields["CODE_FILE"], "journal_test.go") || fields["CODE_LINE"] == "" ||
+		!strings.HasSuffix(fields["CODE_FUNC"], "TestJournalHandler") {
+		t.Errorf("expected code location of the log call, got %v", fields)
+	}
+}
+
+// TestJournalHandlerNilError verifies that typed nil errors are written as "<nil>".
+func TestJournalHandlerNilError(t *testing.T) {
+	var w messageWriter
+	l := slog.New(logging.NewJournalHandler(&w, nil))
+	l.Error("failed", "err", (*os.PathError)(nil))
+
+	if fields := decodeJournal(t, []byte(w.msgs[0])); fields["ERROR"] != "<nil>" {
+		t.Errorf("expected ERROR=<nil>, got %v", fields)
+	}
+}
+
+// TestJournalHandlerReplaceAttr verifies that ReplaceAttr can redact fields.
+func TestJournalHandlerReplaceAttr(t *testing.T) {
+	var w messageWriter
+	opts := &slog.HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 1 && groups[0] == "req" && a.Key == "token" {
+			a.Value = slog.StringValue("***")
+		}
+		return a
+	}}
+	slog.New(logging.NewJournalHandler(&w, opts)).WithGroup("req").Info("login", "token", "abc")
+
+	if fields := decodeJournal(t, []byte(w.msgs[0])); fields["REQ_TOKEN"] != "***" {
+		t.Errorf("expected REQ_TOKEN=***, got %v", fields)
+	}
+}
+
+// TestJournalFieldNames verifies conversion of attribute keys.
+func TestJournalFieldNames(t *testing.T) {
+	var w messageWriter
+	l := slog.New(logging.NewJournalHandler(&w, nil))
+	l.Info("m", "2fa", 1, "_trusted", 2, "user-id", 3, strings.Repeat("k", 70), 4,
+		"priority", "high", "message", "attr", "code_file", "f")
+
+	fields := decodeJournal(t, []byte(w.msgs[0]))
+	for _, name := range []string{"X_2FA", "TRUSTED", "USER_ID", strings.Repeat("K", 64),
+		"X_PRIORITY", "X_MESSAGE", "X_CODE_FILE"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("expected field %s in %v", name, fields)
+		}
+	}
+	if fields["MESSAGE"] != "m" || fields["PRIORITY"] != "6" || !strings.HasSuffix(fields["CODE_FILE"], "journal_test.go") {
+		t.Errorf("expected handler fields not overridden by attributes, got %v", fields)
+	}
+}
+
+// TestJournalWriter verifies delivery to a stand-in journald socket.
+func TestJournalWriter(t *testing.T) {
+	dir, err := os.MkdirTemp("", "journal") // short path for the socket name limit
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "socket")
+	sock, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sock.Close()
+
+	w, err := logging.DialJournal(path)
+	if err != nil {
+		t.Fatalf("DialJournal failed: %v", err)
+	}
+	h := logging.NewJournalHandler(w, nil)
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelError, "boom", 0)); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	sock.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := sock.Read(buf)
+	if err != nil {
+		t.Fatalf("no entry received: %v", err)
+	}
+	if fields := decodeJournal(t, buf[:n]); fields["MESSAGE"] != "boom" || fields["PRIORITY"] != "3" {
+		t.Errorf("unexpected entry %v", fields)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("MESSAGE=x\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expected os.ErrClosed after Close, got %v", err)
+	}
+}
+
+// TestJournalWriterReconnect verifies writes after journald restarted,
+// including a write while the socket was missing.
+func TestJournalWriterReconnect(t *testing.T) {
+	dir, err := os.MkdirTemp("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "socket")
+	listen := func() *net.UnixConn {
+		sock, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sock
+	}
+	sock := listen()
+
+	w, err := logging.DialJournal(path)
+	if err != nil {
+		t.Fatalf("DialJournal failed: %v", err)
+	}
+	defer w.Close()
+
+	// journald stops, writes fail until it is back
+	sock.Close()
+	os.Remove(path)
+	if _, err := w.Write([]byte("MESSAGE=lost\n")); err == nil {
+		t.Fatal("expected error without journal socket")
+	}
+
+	sock = listen()
+	defer sock.Close()
+	if _, err := w.Write([]byte("MESSAGE=back\n")); err != nil {
+		t.Fatalf("Write after restart failed: %v", err)
+	}
+	buf := make([]byte, 64)
+	sock.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := sock.Read(buf)
+	if err != nil {
+		t.Fatalf("no entry received: %v", err)
+	}
+	if got := string(buf[:n]); got != "MESSAGE=back\n" {
+		t.Errorf("unexpected entry %q", got)
+	}
+}
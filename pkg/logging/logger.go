@@ -6,7 +6,9 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/voler88/conslog"
 )
@@ -25,11 +27,17 @@ const (
 // HandlerType represents the type of log output handler.
 type HandlerType string
 
-// Supported handler types for log output formatting.
+// Built-in handler types for log output formatting, more can be added
+// with [RegisterHandler].
 const (
 	Console HandlerType = "console" // custom console handler with pretty output
 	JSON    HandlerType = "json"    // JSON formatted output
 	Text    HandlerType = "text"    // plain text output using slog's standard text handler
+	Logfmt  HandlerType = "logfmt"  // colorized key=value pairs on a single line
+	ECS     HandlerType = "ecs"     // Elastic Common Schema JSON
+	GELF    HandlerType = "gelf"    // Graylog Extended Log Format 1.1 JSON
+	OTLP    HandlerType = "otlp"    // OpenTelemetry log data model in OTLP JSON encoding
+	GCP     HandlerType = "gcp"     // Google Cloud Logging structured JSON
 )
 
 // String implements [fmt.Stringer] for [HandlerType].
@@ -37,13 +45,10 @@ func (h HandlerType) String() string {
 	return string(h)
 }
 
-// IsValid checks if the [HandlerType] is one of the supported types.
+// IsValid checks if the [HandlerType] is built in or registered.
 func (h HandlerType) IsValid() bool {
-	switch h {
-	case Console, Text, JSON:
-		return true
-	}
-	return false
+	_, ok := lookupHandler(h)
+	return ok
 }
 
 // Logger interface for logging with dynamic level control.
@@ -67,48 +72,134 @@ type Logger interface {
 type logger struct {
 	logger *slog.Logger
 	level  *slog.LevelVar
+	clock  conslog.Clock // set by WithClock, nil for the system clock
 }
 
-// NewLogger creates a [Logger] with the specified output writer and handler type.
+// NewLogger creates a [Logger] with the specified output writer and handler type,
+// built in or added by [RegisterHandler].
 // If the handler type is invalid, it logs a warning and falls back to JSON handler.
-func NewLogger(out io.Writer, handler HandlerType) Logger {
+// Options enable optional features such as [WithStackTrace], [WithClock],
+// [WithSource], [WithFilter] and [WithStructuredErrors].
+func NewLogger(out io.Writer, handler HandlerType, options ...Option) Logger {
+	cfg := newConfig(options)
 	lvl := new(slog.LevelVar)
-	opts := &slog.HandlerOptions{Level: lvl}
+	opts := &slog.HandlerOptions{Level: lvl, AddSource: cfg.source}
 
 	var h slog.Handler
-	switch handler {
-	case Console:
-		h = conslog.NewConsoleHandler(out, opts)
-	case Text:
-		h = slog.NewTextHandler(out, opts)
-	case JSON:
-		h = slog.NewJSONHandler(out, opts)
-	default:
-		fmt.Fprintf(os.Stderr, "warning: invalid handler type %q, falling back to JSON\n", handler)
-		h = slog.NewJSONHandler(out, opts)
+	if handler == Console {
+		var consoleOpts []conslog.Option
+		if cfg.stackLevel != nil {
+			consoleOpts = append(consoleOpts, conslog.WithStackTrace(cfg.stackLevel))
+		}
+		if cfg.clock != nil {
+			consoleOpts = append(consoleOpts, conslog.WithClock(cfg.clock))
+		}
+		h = conslog.NewConsoleHandler(out, opts, consoleOpts...)
+	} else {
+		newHandler, ok := lookupHandler(handler)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "warning: invalid handler type %q, falling back to JSON\n", handler)
+			newHandler, _ = lookupHandler(JSON)
+			handler = JSON
+		}
+		if cfg.structuredErrors && handler == JSON {
+			opts.ReplaceAttr = expandErrors(opts.ReplaceAttr)
+		}
+		h = newHandler(out, opts)
+	}
+
+	// console handler supports stacks and clocks natively, others are wrapped
+	if handler != Console {
+		if cfg.stackLevel != nil {
+			h = newStackHandler(h, cfg.stackLevel)
+		}
+		if cfg.clock != nil {
+			h = &clockHandler{h, cfg.clock}
+		}
+	}
+	if cfg.filter != nil {
+		h = cfg.filter.Handler(h)
 	}
 
-	return &logger{slog.New(h), lvl}
+	return &logger{slog.New(h), lvl, cfg.clock}
+}
+
+// NewFromHandler creates a [Logger] writing to a custom handler. The handler
+// must filter records by level so that [Logger.SetLevel] takes effect.
+func NewFromHandler(h slog.Handler, level *slog.LevelVar) Logger {
+	return &logger{slog.New(h), level, nil}
+}
+
+// expandErrors returns a ReplaceAttr function that calls replace, if set,
+// and expands the error values it returns into structured objects with
+// their causes, attributes and stack traces.
+func expandErrors(replace func([]string, slog.Attr) slog.Attr) func([]string, slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if replace != nil {
+			a = replace(groups, a)
+		}
+		if a.Value.Kind() == slog.KindAny {
+			if err, ok := a.Value.Any().(error); ok {
+				a.Value = conslog.ErrorValue(err)
+			}
+		}
+		return a
+	}
 }
 
 // Debug logs a message at Debug level with optional key-value pairs.
 func (l *logger) Debug(msg string, args ...any) {
-	l.logger.Debug(msg, args...)
+	l.logAt(LevelDebug, msg, args, 1)
 }
 
 // Info logs a message at Info level with optional key-value pairs.
 func (l *logger) Info(msg string, args ...any) {
-	l.logger.Info(msg, args...)
+	l.logAt(LevelInfo, msg, args, 1)
 }
 
 // Warn logs a message at Warn level with optional key-value pairs.
 func (l *logger) Warn(msg string, args ...any) {
-	l.logger.Warn(msg, args...)
+	l.logAt(LevelWarn, msg, args, 1)
 }
 
 // Error logs a message at Error level with optional key-value pairs.
 func (l *logger) Error(msg string, args ...any) {
-	l.logger.Error(msg, args...)
+	l.logAt(LevelError, msg, args, 1)
+}
+
+// logAt handles a record with the program counter of the caller skip frames
+// above the caller of logAt, so source locations and stack traces start at
+// the log call.
+func (l *logger) logAt(level Level, msg string, args []any, skip int) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(2+skip, pcs[:]) // skip runtime.Callers and logAt
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = l.logger.Handler().Handle(ctx, r)
+}
+
+// LogAt logs a message at level with the location of its caller. Loggers
+// not created by this package are called with the method for the closest
+// predefined level at or below level, e.g. Warn for LevelWarn+2.
+func LogAt(l Logger, level Level, msg string, args ...any) {
+	if lg, ok := l.(*logger); ok {
+		lg.logAt(level, msg, args, 1)
+		return
+	}
+	switch {
+	case level >= LevelError:
+		l.Error(msg, args...)
+	case level >= LevelWarn:
+		l.Warn(msg, args...)
+	case level >= LevelInfo:
+		l.Info(msg, args...)
+	default:
+		l.Debug(msg, args...)
+	}
 }
 
 // Enabled checks if level handled by logger.
@@ -164,11 +255,11 @@ func (l *logger) SetLevelByName(name string) error {
 // With returns a [Logger] with additional key-value pairs added to the context.
 // It preserves the dynamic log level variable.
 func (l *logger) With(args ...any) Logger {
-	return &logger{l.logger.With(args...), l.level}
+	return &logger{l.logger.With(args...), l.level, l.clock}
 }
 
 // WithGroup returns a [Logger] that nests subsequent attributes under the given group name.
 // It preserves the dynamic log level variable.
 func (l *logger) WithGroup(name string) Logger {
-	return &logger{l.logger.WithGroup(name), l.level}
+	return &logger{l.logger.WithGroup(name), l.level, l.clock}
 }
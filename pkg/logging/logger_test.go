@@ -3,6 +3,7 @@ package logging_test
 import (
 	"bytes"
 	"encoding/json"
+	"log/slog"
 	"regexp"
 	"slices"
 	"strings"
@@ -17,6 +18,7 @@ func TestHandlerTypeValidation(t *testing.T) {
 		logging.Console,
 		logging.Text,
 		logging.JSON,
+		logging.Logfmt,
 	}
 	for _, ht := range validTypes {
 		if !ht.IsValid() {
@@ -65,6 +67,7 @@ func TestHandlers(t *testing.T) {
 		{"JSON", logging.JSON, true},
 		{"Console", logging.Console, false},
 		{"Text", logging.Text, false},
+		{"Logfmt", logging.Logfmt, false},
 	}
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
@@ -341,3 +344,73 @@ func TestEnabled(t *testing.T) {
 		})
 	}
 }
+
+// foreignLogger is a [logging.Logger] not created by the logging package.
+type foreignLogger struct{ logging.Logger }
+
+// TestLogAt verifies that arbitrary levels are logged exactly by package
+// loggers and with the closest predefined level by other loggers.
+func TestLogAt(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+	l.SetLevel(logging.LevelDebug - 4)
+
+	tt := []struct {
+		level       logging.Level
+		wantLevel   string
+		wantForeign string
+	}{
+		{logging.LevelDebug - 4, "DEBUG-4", "DEBUG"},
+		{logging.LevelInfo, "INFO", "INFO"},
+		{logging.LevelInfo + 2, "INFO+2", "INFO"},
+		{logging.LevelWarn, "WARN", "WARN"},
+		{logging.LevelError + 4, "ERROR+4", "ERROR"},
+	}
+	for _, tc := range tt {
+		buf.Reset()
+		logging.LogAt(l, tc.level, "msg")
+		if !strings.Contains(buf.String(), `"level":"`+tc.wantLevel+`"`) {
+			t.Errorf("LogAt(%v): expected level %s, got: %s", tc.level, tc.wantLevel, buf.String())
+		}
+		buf.Reset()
+		logging.LogAt(foreignLogger{l}, tc.level, "msg")
+		if !strings.Contains(buf.String(), `"level":"`+tc.wantForeign+`"`) {
+			t.Errorf("LogAt(%v) on foreign logger: expected level %s, got: %s", tc.level, tc.wantForeign, buf.String())
+		}
+	}
+}
+
+// TestLogAtSource verifies that LogAt reports the location of its caller.
+func TestLogAtSource(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON, logging.WithSource())
+	logging.LogAt(l, logging.LevelWarn+2, "msg")
+
+	var line struct {
+		Source struct {
+			Function string `json:"function"`
+		} `json:"source"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if want := "logging_test.TestLogAtSource"; !strings.HasSuffix(line.Source.Function, want) {
+		t.Errorf("expected source function %s, got %q", want, line.Source.Function)
+	}
+}
+
+// TestNewFromHandler verifies dynamic level control of a custom handler.
+func TestNewFromHandler(t *testing.T) {
+	var buf bytes.Buffer
+	lvl := new(slog.LevelVar)
+	l := logging.NewFromHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: lvl}), lvl)
+
+	l.Debug("hidden")
+	l.SetLevel(logging.LevelDebug)
+	l.With("k", "v").Debug("shown")
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") || !strings.Contains(out, "msg=shown k=v") {
+		t.Errorf("expected only the record after SetLevel, got: %s", out)
+	}
+}
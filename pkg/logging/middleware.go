@@ -0,0 +1,286 @@
+package logging
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// defaultRequestIDHeader is the header read and set for request IDs.
+const defaultRequestIDHeader = "X-Request-ID"
+
+// HTTPOption configures [AccessLogMiddleware] and [NewTransport].
+type HTTPOption func(*httpConfig)
+
+// httpConfig holds HTTP logging settings.
+type httpConfig struct {
+	levels          [6]Level                   // log level indexed by status class
+	skip            []func(*http.Request) bool // requests excluded from logging
+	requestIDHeader string                     // header carrying the request ID
+	headers         []string                   // captured request headers, nil for all
+	captureHeaders  bool                       // log request headers
+	bodyLimit       int                        // captured body bytes, 0 disables capture
+	redactKeys      []string                   // additional names to redact
+	maxRetries      int                        // retries of failed client requests
+	retryBackoff    time.Duration              // base delay between client retries
+}
+
+// newHTTPConfig applies options to a default configuration:
+// 5xx responses are logged at Error, 4xx at Warn and others at Info level.
+func newHTTPConfig(options []HTTPOption) *httpConfig {
+	cfg := &httpConfig{
+		levels:          [6]Level{LevelInfo, LevelInfo, LevelInfo, LevelInfo, LevelWarn, LevelError},
+		requestIDHeader: defaultRequestIDHeader,
+	}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithStatusLevel sets the log level for a status class, e.g. 2 for 2xx responses.
+// Classes outside of 1-5 are ignored.
+func WithStatusLevel(class int, level Level) HTTPOption {
+	return func(c *httpConfig) {
+		if class >= 1 && class <= 5 {
+			c.levels[class] = level
+		}
+	}
+}
+
+// WithSkip excludes requests matching fn from logging.
+func WithSkip(fn func(*http.Request) bool) HTTPOption {
+	return func(c *httpConfig) {
+		c.skip = append(c.skip, fn)
+	}
+}
+
+// WithSkipPaths excludes requests for the exact paths, e.g. health checks.
+func WithSkipPaths(paths ...string) HTTPOption {
+	return WithSkip(func(r *http.Request) bool {
+		return slices.Contains(paths, r.URL.Path)
+	})
+}
+
+// WithRequestIDHeader sets the header used to read and return request IDs,
+// "X-Request-ID" by default. Ignored by [NewTransport].
+func WithRequestIDHeader(name string) HTTPOption {
+	return func(c *httpConfig) {
+		c.requestIDHeader = name
+	}
+}
+
+// WithHeaders logs the named request headers, or all of them if no names
+// are given. Sensitive headers are redacted.
+func WithHeaders(names ...string) HTTPOption {
+	return func(c *httpConfig) {
+		c.captureHeaders = true
+		c.headers = append(c.headers, names...)
+	}
+}
+
+// WithBody logs up to limit bytes of request and response bodies.
+// Sensitive JSON fields are redacted.
+func WithBody(limit int) HTTPOption {
+	return func(c *httpConfig) {
+		c.bodyLimit = limit
+	}
+}
+
+// WithRedact adds header, query parameter and JSON field names whose
+// values are redacted, in addition to common credentials.
+func WithRedact(names ...string) HTTPOption {
+	return func(c *httpConfig) {
+		c.redactKeys = append(c.redactKeys, names...)
+	}
+}
+
+// AccessLogMiddleware returns HTTP middleware that logs each request with its
+// method, path, status, response size, duration, remote address and request ID.
+// The request ID is taken from the request header or generated, and returned
+// in the response header. The trace context is taken from the request context,
+// see [ExtractTrace], or the traceparent header. A child logger with request
+// and trace attributes is stored in the request context and available with
+// [FromContext]. The response writer passed to the handler implements
+// [http.Flusher], [http.Hijacker] and [io.ReaderFrom] of the underlying
+// writer, other interfaces are reached with [http.ResponseController].
+func AccessLogMiddleware(l Logger, options ...HTTPOption) func(http.Handler) http.Handler {
+	cfg := newHTTPConfig(options)
+	red := newRedactor(cfg.redactKeys)
+	clock := ClockOf(l)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, skip := range cfg.skip {
+				if skip(r) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			start := clock.Now()
+			requestID := r.Header.Get(cfg.requestIDHeader)
+			if requestID == "" {
+				requestID = newRequestID()
+			}
+			w.Header().Set(cfg.requestIDHeader, requestID)
+
+			ctx := r.Context()
+			if _, ok := ExtractTrace(ctx); !ok {
+				if tc, err := ParseTraceparent(r.Header.Get(TraceparentHeader)); err == nil {
+					ctx = ContextWithTrace(ctx, tc)
+				}
+			}
+
+			reqLogger := l.With("request_id", requestID, "method", r.Method, "path", r.URL.Path)
+			reqLogger = TraceLogger(ctx, reqLogger)
+			r = r.WithContext(NewContext(ctx, reqLogger))
+
+			var reqBody *limitedBuffer
+			if cfg.bodyLimit > 0 && r.Body != nil && r.Body != http.NoBody {
+				reqBody = &limitedBuffer{max: cfg.bodyLimit}
+				r.Body = readCloser{io.TeeReader(r.Body, reqBody), r.Body}
+			}
+
+			rw := &responseWriter{ResponseWriter: w}
+			if cfg.bodyLimit > 0 {
+				rw.body = &limitedBuffer{max: cfg.bodyLimit}
+			}
+
+			next.ServeHTTP(rw, r)
+
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK // nothing written, net/http replies 200
+			}
+
+			args := []any{
+				"status", status,
+				"bytes", rw.bytes,
+				"duration", clock.Now().Sub(start),
+				"remote_addr", r.RemoteAddr,
+			}
+			if cfg.captureHeaders {
+				args = append(args, slog.Attr{
+					Key:   "headers",
+					Value: slog.GroupValue(red.headerAttrs(r.Header, cfg.headers)...),
+				})
+			}
+			if reqBody != nil {
+				args = append(args, reqBody.bodyAttr("request_body", red))
+			}
+			if rw.body != nil && rw.body.Len() > 0 {
+				args = append(args, rw.body.bodyAttr("response_body", red))
+			}
+
+			LogAt(reqLogger, cfg.levels[statusClass(status)], "http request", args...)
+		})
+	}
+}
+
+// statusClass returns the class of an HTTP status code, 5 for invalid codes.
+func statusClass(status int) int {
+	class := status / 100
+	if class < 1 || class > 5 {
+		return 5
+	}
+	return class
+}
+
+// newRequestID returns a random 16 character hex request ID.
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:]) // never returns an error
+	return hex.EncodeToString(b[:])
+}
+
+// readCloser combines a wrapping reader, e.g. [io.TeeReader],
+// with the closer of the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// responseWriter records the status code, written bytes and optionally
+// the body of a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+	body   *limitedBuffer
+}
+
+// WriteHeader records the final status code, implements [http.ResponseWriter].
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 && status >= http.StatusOK {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records written bytes, implements [http.ResponseWriter].
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += n
+	if w.body != nil {
+		_, _ = w.body.Write(p[:n])
+	}
+	return n, err
+}
+
+// ReadFrom records copied bytes, implements [io.ReaderFrom] so the underlying
+// writer can use sendfile when the body is not captured.
+func (w *responseWriter) ReadFrom(src io.Reader) (int64, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.body != nil {
+		src = io.TeeReader(src, w.body)
+	}
+	var (
+		n   int64
+		err error
+	)
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		n, err = io.Copy(w.ResponseWriter, src)
+	}
+	w.bytes += int(n)
+	return n, err
+}
+
+// Hijack implements [http.Hijacker] if the underlying writer supports it,
+// the connection is logged with status 101 Switching Protocols.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Flush implements [http.Flusher] if the underlying writer supports it.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for [http.ResponseController].
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,284 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logging"
+	"github.com/voler88/conslog/pkg/logtest"
+)
+
+// accessLine holds fields of a JSON access log record.
+type accessLine struct {
+	Level        string            `json:"level"`
+	Msg          string            `json:"msg"`
+	Method       string            `json:"method"`
+	Path         string            `json:"path"`
+	Status       int               `json:"status"`
+	Bytes        int               `json:"bytes"`
+	Duration     int64             `json:"duration"`
+	RemoteAddr   string            `json:"remote_addr"`
+	RequestID    string            `json:"request_id"`
+	Headers      map[string]string `json:"headers"`
+	RequestBody  string            `json:"request_body"`
+	ResponseBody string            `json:"response_body"`
+}
+
+// serve runs a request through the access log middleware and returns logged records.
+func serve(
+	t *testing.T,
+	handler http.HandlerFunc,
+	req *http.Request,
+	options ...logging.HTTPOption,
+) (*httptest.ResponseRecorder, []accessLine) {
+	t.Helper()
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+	l.SetLevel(logging.LevelDebug)
+
+	rec := httptest.NewRecorder()
+	logging.AccessLogMiddleware(l, options...)(handler).ServeHTTP(rec, req)
+
+	var lines []accessLine
+	for line := range strings.Lines(buf.String()) {
+		var entry accessLine
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("failed to parse log line: %v: %s", err, line)
+		}
+		lines = append(lines, entry)
+	}
+	return rec, lines
+}
+
+// TestAccessLogMiddleware verifies logged request attributes and levels by status class.
+func TestAccessLogMiddleware(t *testing.T) {
+	tt := []struct {
+		name      string
+		status    int
+		options   []logging.HTTPOption
+		wantLevel string
+	}{
+		{"OK", http.StatusOK, nil, "INFO"},
+		{"Redirect", http.StatusFound, nil, "INFO"},
+		{"NotFound", http.StatusNotFound, nil, "WARN"},
+		{"ServerError", http.StatusBadGateway, nil, "ERROR"},
+		{"CustomLevel", http.StatusOK, []logging.HTTPOption{logging.WithStatusLevel(2, logging.LevelDebug)}, "DEBUG"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/items?id=1", nil)
+			req.Header.Set("X-Request-ID", "req-1")
+
+			rec, lines := serve(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = io.WriteString(w, "hello")
+			}, req, tc.options...)
+
+			if len(lines) != 1 {
+				t.Fatalf("expected 1 log line, got %d", len(lines))
+			}
+			got := lines[0]
+			if got.Level != tc.wantLevel {
+				t.Errorf("expected level %s, got %s", tc.wantLevel, got.Level)
+			}
+			if got.Method != http.MethodGet || got.Path != "/items" || got.Status != tc.status ||
+				got.Bytes != 5 || got.RequestID != "req-1" || got.RemoteAddr == "" || got.Duration <= 0 {
+				t.Errorf("unexpected access log record: %+v", got)
+			}
+			if rec.Header().Get("X-Request-ID") != "req-1" {
+				t.Errorf("expected request ID in response header, got %q", rec.Header().Get("X-Request-ID"))
+			}
+		})
+	}
+}
+
+// TestAccessLogMiddlewareClock verifies durations measured with the logger clock.
+func TestAccessLogMiddlewareClock(t *testing.T) {
+	clock := logtest.NewClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON, logging.WithClock(clock))
+
+	handler := logging.AccessLogMiddleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clock.Advance(250 * time.Millisecond)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var got accessLine
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to parse log line: %v: %s", err, buf.String())
+	}
+	if got.Duration != int64(250*time.Millisecond) {
+		t.Errorf("expected duration of 250ms on the fake clock, got %d", got.Duration)
+	}
+}
+
+// TestAccessLogMiddlewareRequestID verifies request ID generation and custom headers.
+func TestAccessLogMiddlewareRequestID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec, lines := serve(t, func(w http.ResponseWriter, r *http.Request) {}, req,
+		logging.WithRequestIDHeader("X-Trace"))
+
+	if len(lines) != 1 || len(lines[0].RequestID) != 16 {
+		t.Fatalf("expected generated request ID, got: %+v", lines)
+	}
+	if rec.Header().Get("X-Trace") != lines[0].RequestID {
+		t.Errorf("expected generated request ID in response header, got %q", rec.Header().Get("X-Trace"))
+	}
+	if lines[0].Status != http.StatusOK {
+		t.Errorf("expected implicit status 200, got %d", lines[0].Status)
+	}
+}
+
+// TestAccessLogMiddlewareSkip verifies that skipped requests are not logged.
+func TestAccessLogMiddlewareSkip(t *testing.T) {
+	var called bool
+	handler := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+	_, lines := serve(t, handler, httptest.NewRequest(http.MethodGet, "/healthz", nil),
+		logging.WithSkipPaths("/healthz", "/readyz"))
+	if len(lines) != 0 || !called {
+		t.Errorf("expected skipped request to be served but not logged, got %d lines", len(lines))
+	}
+
+	_, lines = serve(t, handler, httptest.NewRequest(http.MethodOptions, "/", nil),
+		logging.WithSkip(func(r *http.Request) bool { return r.Method == http.MethodOptions }))
+	if len(lines) != 0 {
+		t.Errorf("expected custom skip rule to apply, got %d lines", len(lines))
+	}
+}
+
+// TestAccessLogMiddlewareCapture verifies header and body capture.
+func TestAccessLogMiddlewareCapture(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("ping"))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Accept", "*/*")
+
+	var gotBody string
+	_, lines := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		_, _ = io.WriteString(w, "pong")
+	}, req, logging.WithHeaders("content-type"), logging.WithBody(64))
+
+	if gotBody != "ping" {
+		t.Errorf("expected handler to read full body, got %q", gotBody)
+	}
+	got := lines[0]
+	if got.Headers["Content-Type"] != "text/plain" || len(got.Headers) != 1 {
+		t.Errorf("expected only Content-Type header, got %v", got.Headers)
+	}
+	if got.RequestBody != "ping" || got.ResponseBody != "pong" {
+		t.Errorf("expected captured bodies, got request %q response %q", got.RequestBody, got.ResponseBody)
+	}
+}
+
+// TestAccessLogMiddlewareReadFrom verifies that copied responses are counted and captured.
+func TestAccessLogMiddlewareReadFrom(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/file", nil)
+	_, lines := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		rf, ok := w.(io.ReaderFrom)
+		if !ok {
+			t.Fatal("expected wrapped writer to implement io.ReaderFrom")
+		}
+		_, _ = rf.ReadFrom(strings.NewReader("contents"))
+	}, req, logging.WithBody(64))
+
+	if got := lines[0]; got.Status != http.StatusOK || got.Bytes != 8 || got.ResponseBody != "contents" {
+		t.Errorf("expected copied response in record, got %+v", got)
+	}
+}
+
+// TestAccessLogMiddlewareHijack verifies that connections can be hijacked.
+func TestAccessLogMiddlewareHijack(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+	h := logging.AccessLogMiddleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("hijack failed: %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		_ = rw.Flush()
+	}))
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "test")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("expected 101 response, got %d", resp.StatusCode)
+	}
+
+	<-done
+	var got accessLine
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to parse log line: %v: %s", err, buf.String())
+	}
+	if got.Status != http.StatusSwitchingProtocols {
+		t.Errorf("expected status 101 in record, got %+v", got)
+	}
+}
+
+// TestAccessLogMiddlewareContextLogger verifies the request scoped logger in the context.
+func TestAccessLogMiddlewareContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+
+	h := logging.AccessLogMiddleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logging.FromContext(r.Context()).Info("inside handler")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/ctx", nil)
+	req.Header.Set("X-Request-ID", "ctx-1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	first, _, _ := strings.Cut(buf.String(), "\n")
+	var line accessLine
+	if err := json.Unmarshal([]byte(first), &line); err != nil {
+		t.Fatalf("failed to parse log line: %v", err)
+	}
+	if line.Msg != "inside handler" || line.RequestID != "ctx-1" || line.Path != "/ctx" {
+		t.Errorf("expected handler record with request attributes, got: %s", first)
+	}
+}
+
+// TestAccessLogMiddlewareTrace verifies trace attributes from the traceparent header.
+func TestAccessLogMiddlewareTrace(t *testing.T) {
+	var ctxTrace string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if tc, ok := logging.TraceFromContext(r.Context()); ok {
+			ctxTrace = tc.String()
+		}
+	}
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest(http.MethodGet, "/traced", nil)
+	req.Header.Set("traceparent", traceparent)
+
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+	logging.AccessLogMiddleware(l)(http.HandlerFunc(handler)).ServeHTTP(httptest.NewRecorder(), req)
+
+	if ctxTrace != traceparent {
+		t.Errorf("expected trace context in request context, got %q", ctxTrace)
+	}
+	if !strings.Contains(buf.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7"`) {
+		t.Errorf("expected trace attributes, got: %s", buf.String())
+	}
+}
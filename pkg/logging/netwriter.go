@@ -0,0 +1,584 @@
+package logging
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Defaults of [NetWriter] options.
+const (
+	defaultNetBuffer   = 1 << 20 // 1 MiB
+	defaultMinBackoff  = 100 * time.Millisecond
+	defaultMaxBackoff  = 30 * time.Second
+	defaultNetTimeout  = 5 * time.Second
+	spillReadChunkSize = 64 << 10
+)
+
+// NetOption configures [NewNetWriter].
+type NetOption func(*NetWriter)
+
+// WithTLS encrypts stream connections with cfg.
+func WithTLS(cfg *tls.Config) NetOption {
+	return func(w *NetWriter) {
+		w.tls = cfg
+	}
+}
+
+// WithBufferSize limits the records held in memory while the collector is
+// unreachable to n bytes, 1 MiB by default. The oldest records beyond the
+// limit are moved to the spill file of [WithSpill] or dropped, except those
+// of the batch being sent.
+func WithBufferSize(n int) NetOption {
+	return func(w *NetWriter) {
+		w.maxBuffer = n
+	}
+}
+
+// WithSpill keeps records exceeding the memory buffer in the file at path,
+// up to maxBytes, and sends them first once the collector is reachable.
+// Records left in the file by a previous process are sent as well.
+func WithSpill(path string, maxBytes int64) NetOption {
+	return func(w *NetWriter) {
+		w.spillPath = path
+		w.spillMax = maxBytes
+	}
+}
+
+// WithBackoff sets the delays between reconnection attempts, doubling from
+// minDelay up to maxDelay, 100ms and 30s by default.
+func WithBackoff(minDelay, maxDelay time.Duration) NetOption {
+	return func(w *NetWriter) {
+		w.minBackoff = minDelay
+		w.maxBackoff = maxDelay
+	}
+}
+
+// WithTimeout limits connecting and each batch of writes to d, 5s by
+// default. [NetWriter.Close] gives up sending after d as well.
+func WithTimeout(d time.Duration) NetOption {
+	return func(w *NetWriter) {
+		w.timeout = d
+	}
+}
+
+// NetWriter ships newline-delimited records to a collector over TCP, UDP or
+// unix sockets, e.g. as the output of [NewLogger] with the [JSON] handler.
+// Writes never block on the network: records are buffered and sent in
+// batches by a background goroutine, which reconnects with exponential
+// backoff when the collector is unreachable. Datagram networks send one
+// record per datagram. Delivery is at least once, records may be repeated
+// after a connection broke. It is safe for concurrent use.
+type NetWriter struct {
+	network    string
+	addr       string
+	datagram   bool
+	tls        *tls.Config
+	maxBuffer  int
+	spillPath  string
+	spillMax   int64
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	timeout    time.Duration
+
+	mu          sync.Mutex
+	pending     [][]byte // records in memory, oldest first
+	size        int      // bytes of pending
+	inflight    int      // records of pending in the batch being sent, older than the spill file
+	spill       *os.File // records between the batch and the rest of pending, nil without spill
+	spillSize   int64    // bytes written to the spill file
+	spillSent   int64    // bytes of the spill file already sent
+	spillQueue  [][]byte // records to append to the spill file
+	spillQueued int64    // bytes of spillQueue
+	conn        net.Conn // connection of the sender, interrupted by Close
+	closed      bool
+	dropped     atomic.Int64
+	spillMu     sync.Mutex // serializes spill file I/O, acquired before mu
+
+	wake   chan struct{} // signals new records
+	done   chan struct{} // closed by Close
+	exited chan struct{} // closed when the sender returns
+}
+
+// NewNetWriter returns a writer shipping records to addr, connecting in the
+// background. Network is one of "tcp", "tcp4", "tcp6", "udp", "udp4",
+// "udp6", "unix" or "unixgram".
+func NewNetWriter(network, addr string, options ...NetOption) (*NetWriter, error) {
+	w := &NetWriter{
+		network:    network,
+		addr:       addr,
+		maxBuffer:  defaultNetBuffer,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+		timeout:    defaultNetTimeout,
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		exited:     make(chan struct{}),
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+	case "udp", "udp4", "udp6", "unixgram":
+		if w.tls != nil {
+			return nil, fmt.Errorf("TLS needs a stream network, not %q", network)
+		}
+		w.datagram = true
+	default:
+		return nil, fmt.Errorf("unsupported network %q", network)
+	}
+
+	if w.spillPath != "" {
+		f, err := os.OpenFile(w.spillPath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		w.spill, w.spillSize = f, fi.Size()
+		if w.spillSize > 0 {
+			w.wake <- struct{}{} // send records of a previous process
+		}
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// Write queues p as one record, adding a trailing newline if missing,
+// implements [io.Writer] interface.
+func (w *NetWriter) Write(p []byte) (int, error) {
+	rec := make([]byte, len(p), len(p)+1)
+	copy(rec, p)
+	if len(rec) == 0 || rec[len(rec)-1] != '\n' {
+		rec = append(rec, '\n')
+	}
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, os.ErrClosed
+	}
+	w.pending = append(w.pending, rec)
+	w.size += len(rec)
+	w.evict()
+	spill := len(w.spillQueue) > 0
+	w.mu.Unlock()
+
+	if spill {
+		w.writeSpill(false) // outside mu, Write only waits for the disk on overflow
+	}
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of records lost because the buffers were
+// full or the collector was unreachable when the writer was closed.
+func (w *NetWriter) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// Close sends buffered records if the collector accepts them within the
+// timeout of [WithTimeout] and stops the writer. Records that could not be sent are kept in
+// the spill file if configured. Later writes return [os.ErrClosed].
+// Implements [io.Closer] interface.
+func (w *NetWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	if w.conn != nil {
+		w.conn.SetWriteDeadline(time.Now()) // interrupt a blocked batch
+	}
+	w.mu.Unlock()
+
+	close(w.done)
+	<-w.exited
+
+	err := w.persist()
+	if w.spill != nil {
+		return errors.Join(err, w.spill.Close())
+	}
+	return err
+}
+
+// evict removes the oldest records not in flight while the memory buffer
+// is exceeded, queueing them for the spill file or dropping them if there
+// is no spill file or it is full. Must be called with mu held.
+func (w *NetWriter) evict() {
+	n := 0
+	for i := w.inflight; i < len(w.pending) && w.size > w.maxBuffer; i++ {
+		w.size -= len(w.pending[i])
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	evicted := slices.Clone(w.pending[w.inflight : w.inflight+n])
+	if w.inflight == 0 {
+		w.pending = w.pending[n:]
+	} else {
+		w.pending = append(w.pending[:w.inflight], w.pending[w.inflight+n:]...)
+	}
+
+	for _, rec := range evicted {
+		if w.spill == nil || w.spillSize+w.spillQueued+int64(len(rec)) > w.spillMax {
+			w.dropped.Add(1)
+			continue
+		}
+		w.spillQueue = append(w.spillQueue, rec)
+		w.spillQueued += int64(len(rec))
+	}
+}
+
+// writeSpill appends the queued records to the spill file. Without wait it
+// returns if another goroutine is writing, which takes the records as well.
+func (w *NetWriter) writeSpill(wait bool) {
+	if wait {
+		w.spillMu.Lock()
+	} else if !w.spillMu.TryLock() {
+		return
+	}
+	defer w.spillMu.Unlock()
+
+	for {
+		w.mu.Lock()
+		recs, size := w.spillQueue, w.spillSize
+		w.spillQueue = nil
+		w.mu.Unlock()
+		if len(recs) == 0 {
+			return
+		}
+
+		b := bytes.Join(recs, nil)
+		_, err := w.spill.Write(b)
+		if err != nil {
+			w.spill.Truncate(size) // remove a partial record
+			w.dropped.Add(int64(len(recs)))
+		}
+		w.mu.Lock()
+		w.spillQueued -= int64(len(b))
+		if err == nil {
+			w.spillSize += int64(len(b))
+		}
+		w.mu.Unlock()
+	}
+}
+
+// persist keeps the records not sent in the spill file in order, rewriting
+// it if the records of a failed batch go first or records were sent, or
+// drops them if there is no spill file or it is full.
+func (w *NetWriter) persist() error {
+	w.spillMu.Lock()
+	defer w.spillMu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	head := w.pending[:w.inflight]
+	tail := slices.Concat(w.spillQueue, w.pending[w.inflight:])
+	w.pending, w.size, w.inflight = nil, 0, 0
+	w.spillQueue, w.spillQueued = nil, 0
+	if w.spill == nil {
+		w.dropped.Add(int64(len(head) + len(tail)))
+		return nil
+	}
+
+	budget := w.spillMax - (w.spillSize - w.spillSent)
+	keep := func(recs [][]byte) []byte {
+		var b []byte
+		for _, rec := range recs {
+			if int64(len(rec)) > budget {
+				w.dropped.Add(1)
+				continue
+			}
+			b = append(b, rec...)
+			budget -= int64(len(rec))
+		}
+		return b
+	}
+	headBytes, tailBytes := keep(head), keep(tail)
+
+	if len(headBytes) == 0 && w.spillSent == 0 {
+		_, err := w.spill.Write(tailBytes)
+		return err
+	}
+	return w.rewriteSpill(headBytes, tailBytes)
+}
+
+// rewriteSpill replaces the spill file by head, the records of the spill
+// file not sent and tail. Must be called with spillMu and mu held.
+func (w *NetWriter) rewriteSpill(head, tail []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(w.spillPath), filepath.Base(w.spillPath)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(head)
+	if err == nil {
+		_, err = io.Copy(tmp, io.NewSectionReader(w.spill, w.spillSent, w.spillSize-w.spillSent))
+	}
+	if err == nil {
+		_, err = tmp.Write(tail)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), w.spillPath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// run sends queued records until the writer is closed.
+func (w *NetWriter) run() {
+	defer close(w.exited)
+
+	var conn net.Conn
+	backoff := w.minBackoff
+	for {
+		select {
+		case <-w.wake:
+		case <-w.done:
+			w.shutdown(conn)
+			return
+		}
+
+		for w.hasRecords() {
+			select {
+			case <-w.done:
+				w.shutdown(conn)
+				return
+			default:
+			}
+
+			err := w.deliver(&conn)
+			if err == nil {
+				backoff = w.minBackoff
+				continue
+			}
+			select {
+			case <-time.After(backoff):
+			case <-w.done:
+				w.shutdown(conn)
+				return
+			}
+			backoff = min(backoff*2, w.maxBackoff)
+		}
+	}
+}
+
+// deliver connects to the collector if *conn is nil and flushes queued
+// records. The connection is closed and reset to nil on failure.
+func (w *NetWriter) deliver(conn *net.Conn) error {
+	if *conn == nil {
+		c, err := w.dial(time.Now().Add(w.timeout))
+		if err != nil {
+			return err
+		}
+		*conn = c
+		w.mu.Lock()
+		w.conn = c
+		w.mu.Unlock()
+	}
+	(*conn).SetWriteDeadline(time.Now().Add(w.timeout))
+	if err := w.flush(*conn); err != nil {
+		(*conn).Close()
+		*conn = nil
+		return err
+	}
+	return nil
+}
+
+// shutdown makes a last attempt to send queued records, connecting and
+// writing within the timeout, and closes conn.
+func (w *NetWriter) shutdown(conn net.Conn) {
+	if conn != nil {
+		defer conn.Close()
+	}
+	if !w.hasRecords() {
+		return
+	}
+	deadline := time.Now().Add(w.timeout)
+	if conn == nil {
+		c, err := w.dial(deadline)
+		if err != nil {
+			return
+		}
+		conn = c
+		defer conn.Close()
+	}
+	conn.SetWriteDeadline(deadline)
+	w.flush(conn)
+}
+
+// hasRecords reports whether records wait in memory or the spill file.
+func (w *NetWriter) hasRecords() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending) > 0 || w.spillSent < w.spillSize || len(w.spillQueue) > 0
+}
+
+// dial connects to the collector before deadline.
+func (w *NetWriter) dial(deadline time.Time) (net.Conn, error) {
+	d := &net.Dialer{Deadline: deadline}
+	if w.tls != nil {
+		return tls.DialWithDialer(d, w.network, w.addr, w.tls)
+	}
+	return d.Dial(w.network, w.addr)
+}
+
+// flush sends the records of a failed batch, the spill file and then the
+// records in memory, in the order they were written.
+func (w *NetWriter) flush(conn net.Conn) error {
+	for {
+		if err := w.sendBatch(conn); err != nil {
+			return err
+		}
+		if err := w.flushSpill(conn); err != nil {
+			return err
+		}
+
+		w.mu.Lock()
+		if w.spillSent < w.spillSize || len(w.spillQueue) > 0 {
+			w.mu.Unlock()
+			continue // records spilled meanwhile go first
+		}
+		w.inflight = len(w.pending)
+		w.mu.Unlock()
+		return w.sendBatch(conn)
+	}
+}
+
+// sendBatch sends the records in flight and removes the sent ones from
+// memory. Records not sent stay in flight to be sent first next time.
+func (w *NetWriter) sendBatch(conn net.Conn) error {
+	w.mu.Lock()
+	batch := w.pending[:w.inflight:w.inflight] // evict leaves records in flight alone
+	w.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	unsent, err := w.send(conn, batch)
+	sent := len(batch) - len(unsent)
+	w.mu.Lock()
+	for _, rec := range batch[:sent] {
+		w.size -= len(rec)
+	}
+	w.pending = w.pending[sent:]
+	w.inflight -= sent
+	w.mu.Unlock()
+	return err
+}
+
+// flushSpill sends the spill file in chunks of whole records and empties
+// it once everything was sent.
+func (w *NetWriter) flushSpill(conn net.Conn) error {
+	if w.spill == nil {
+		return nil
+	}
+	w.writeSpill(true)
+	for {
+		w.spillMu.Lock()
+		w.mu.Lock()
+		offset, size := w.spillSent, w.spillSize
+		w.mu.Unlock()
+		if offset >= size {
+			w.spillMu.Unlock()
+			return nil
+		}
+		chunk, err := w.readSpill(offset, size)
+		w.spillMu.Unlock()
+		if len(chunk) == 0 {
+			return err
+		}
+
+		unsent, err := w.send(conn, bytes.SplitAfter(chunk, []byte{'\n'}))
+		sent := int64(len(chunk))
+		for _, rec := range unsent {
+			sent -= int64(len(rec))
+		}
+
+		w.spillMu.Lock()
+		w.mu.Lock()
+		w.spillSent += sent
+		empty := w.spillSent >= w.spillSize
+		if empty {
+			w.spillSize, w.spillSent = 0, 0
+		}
+		w.mu.Unlock()
+		if empty {
+			w.spill.Truncate(0)
+		}
+		w.spillMu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readSpill returns the records of the spill file from offset up to size,
+// at least one complete record. Must be called with spillMu held.
+func (w *NetWriter) readSpill(offset, size int64) ([]byte, error) {
+	n := int64(spillReadChunkSize)
+	for {
+		chunk := make([]byte, min(n, size-offset))
+		read, err := w.spill.ReadAt(chunk, offset)
+		chunk = chunk[:read]
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			return chunk[:i+1], nil
+		}
+		if err != nil || offset+int64(read) >= size {
+			return chunk, err
+		}
+		n *= 2 // record larger than the chunk
+	}
+}
+
+// send writes records to conn, as a single batch on streams and one
+// datagram per record otherwise. Returns the records not sent completely.
+// Datagrams too large for the network are dropped.
+func (w *NetWriter) send(conn net.Conn, recs [][]byte) ([][]byte, error) {
+	if !w.datagram {
+		// WriteTo consumes the buffers, copy them to requeue whole records
+		bufs := append(net.Buffers(nil), recs...)
+		_, err := bufs.WriteTo(conn)
+		return recs[len(recs)-len(bufs):], err
+	}
+
+	for i, rec := range recs {
+		if len(rec) == 0 {
+			continue
+		}
+		if _, err := conn.Write(rec); err != nil {
+			if errors.Is(err, syscall.EMSGSIZE) {
+				w.dropped.Add(1)
+				continue
+			}
+			return recs[i:], err
+		}
+	}
+	return nil, nil
+}
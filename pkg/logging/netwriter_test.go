@@ -0,0 +1,410 @@
+package logging_test
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// collect serves ln and returns a channel receiving each line sent by
+// clients.
+func collect(t *testing.T, ln net.Listener) <-chan string {
+	t.Helper()
+	t.Cleanup(func() { ln.Close() })
+	lines := make(chan string, 64)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				sc := bufio.NewScanner(conn)
+				for sc.Scan() {
+					lines <- sc.Text()
+				}
+			}()
+		}
+	}()
+	return lines
+}
+
+// expectLines fails unless lines receives want in order.
+func expectLines(t *testing.T, lines <-chan string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-lines:
+			if got != w {
+				t.Fatalf("expected line %q, got %q", w, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("line %q not received", w)
+		}
+	}
+}
+
+// unusedAddr returns a local TCP address nothing listens on.
+func unusedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// TestNetWriterLogger verifies delivery of JSON records over TCP.
+func TestNetWriterLogger(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := collect(t, ln)
+
+	w, err := logging.NewNetWriter("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	l := logging.NewLogger(w, logging.JSON)
+	l.Info("hello", "k", "v")
+
+	select {
+	case line := <-lines:
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+		if rec["msg"] != "hello" || rec["k"] != "v" {
+			t.Errorf("unexpected record %v", rec)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no record received")
+	}
+}
+
+// TestNetWriterReconnect verifies that records written while the collector
+// is down are buffered in memory and the spill file and sent in order once
+// it is up.
+func TestNetWriterReconnect(t *testing.T) {
+	addr := unusedAddr(t)
+	spill := filepath.Join(t.TempDir(), "spill")
+	w, err := logging.NewNetWriter("tcp", addr,
+		logging.WithBufferSize(32),
+		logging.WithSpill(spill, 1<<20),
+		logging.WithBackoff(10*time.Millisecond, 50*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	var want []string
+	for i := range 10 {
+		want = append(want, fmt.Sprintf("record %d", i))
+		if _, err := w.Write([]byte(want[i] + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectLines(t, collect(t, ln), want...)
+	if n := w.Dropped(); n != 0 {
+		t.Errorf("expected no dropped records, got %d", n)
+	}
+}
+
+// TestNetWriterDropped verifies that records exceeding the memory buffer
+// are dropped without spill file.
+func TestNetWriterDropped(t *testing.T) {
+	w, err := logging.NewNetWriter("tcp", unusedAddr(t), logging.WithBufferSize(16))
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+	for range 4 {
+		w.Write([]byte("0123456789"))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := w.Dropped(); n != 4 {
+		t.Errorf("expected 4 dropped records, got %d", n)
+	}
+	if _, err := w.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expected os.ErrClosed after Close, got %v", err)
+	}
+}
+
+// TestNetWriterDropOldest verifies that the oldest records are dropped when
+// the memory buffer overflows.
+func TestNetWriterDropOldest(t *testing.T) {
+	addr := unusedAddr(t)
+	w, err := logging.NewNetWriter("tcp", addr,
+		logging.WithBufferSize(27), // three records
+		logging.WithBackoff(10*time.Millisecond, 50*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+	defer w.Close()
+	for i := range 10 {
+		fmt.Fprintf(w, "record %d", i)
+	}
+	if n := w.Dropped(); n != 7 {
+		t.Errorf("expected 7 dropped records, got %d", n)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectLines(t, collect(t, ln), "record 7", "record 8", "record 9")
+}
+
+// TestNetWriterSpillOrder verifies that records of a batch cut off by Close
+// are kept in the spill file before newer records.
+func TestNetWriterSpillOrder(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		var conns []net.Conn
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				for _, c := range conns {
+					c.Close()
+				}
+				return
+			}
+			conns = append(conns, conn) // keep open without reading
+		}
+	}()
+
+	spill := filepath.Join(t.TempDir(), "spill")
+	w, err := logging.NewNetWriter("tcp", ln.Addr().String(),
+		logging.WithBufferSize(1<<20),
+		logging.WithSpill(spill, 1<<30),
+		logging.WithTimeout(300*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+	pad := bytes.Repeat([]byte("x"), 1000)
+	const n = 20000 // 20 MB, more than the socket buffers take
+	for i := range n {
+		fmt.Fprintf(w, "%06d %s", i, pad)
+	}
+	time.Sleep(100 * time.Millisecond) // let a batch block
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if d := w.Dropped(); d != 0 {
+		t.Errorf("expected no dropped records, got %d", d)
+	}
+
+	f, err := os.Open(spill)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	prev := -1
+	for sc.Scan() {
+		var i int
+		fmt.Sscanf(sc.Text(), "%d", &i)
+		if i != prev+1 && prev >= 0 {
+			t.Fatalf("record %d follows record %d in spill file", i, prev)
+		}
+		prev = i
+	}
+	if prev != n-1 {
+		t.Errorf("expected last record %d in spill file, got %d", n-1, prev)
+	}
+}
+
+// TestNetWriterStalledCollector verifies that Close gives up on a collector
+// that accepts connections but never reads.
+func TestNetWriterStalledCollector(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		var conns []net.Conn
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				for _, c := range conns {
+					c.Close()
+				}
+				return
+			}
+			conns = append(conns, conn) // keep open without reading
+		}
+	}()
+
+	w, err := logging.NewNetWriter("tcp", ln.Addr().String(),
+		logging.WithBufferSize(64<<20),
+		logging.WithTimeout(200*time.Millisecond),
+		logging.WithBackoff(10*time.Millisecond, 10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+	rec := bytes.Repeat([]byte("x"), 64<<10)
+	for range 512 { // 32 MiB, more than the socket buffers take
+		w.Write(rec)
+	}
+	time.Sleep(500 * time.Millisecond) // let batches time out and reconnect
+
+	start := time.Now()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("Close took %v", d)
+	}
+	if w.Dropped() == 0 {
+		t.Error("expected records dropped without spill file")
+	}
+}
+
+// TestNetWriterSpillRestart verifies that records left in the spill file
+// are sent by the next writer.
+func TestNetWriterSpillRestart(t *testing.T) {
+	addr := unusedAddr(t)
+	spill := filepath.Join(t.TempDir(), "spill")
+
+	w, err := logging.NewNetWriter("tcp", addr, logging.WithSpill(spill, 1<<20))
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+	w.Write([]byte("a"))
+	w.Write([]byte("b"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(spill); string(b) != "a\nb\n" {
+		t.Fatalf("expected records in spill file, got %q", b)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := collect(t, ln)
+	w, err = logging.NewNetWriter("tcp", addr, logging.WithSpill(spill, 1<<20))
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+	w.Write([]byte("c"))
+	expectLines(t, lines, "a", "b", "c")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(spill); len(b) != 0 {
+		t.Errorf("expected empty spill file, got %q", b)
+	}
+}
+
+// TestNetWriterUDP verifies one datagram per record.
+func TestNetWriterUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w, err := logging.NewNetWriter("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+	defer w.Close()
+	w.Write([]byte("one"))
+	w.Write([]byte("two\n"))
+
+	buf := make([]byte, 1024)
+	for _, want := range []string{"one\n", "two\n"} {
+		pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("no datagram received: %v", err)
+		}
+		if got := string(buf[:n]); got != want {
+			t.Errorf("expected datagram %q, got %q", want, got)
+		}
+	}
+}
+
+// TestNetWriterTLS verifies delivery over TLS.
+func TestNetWriterTLS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "collector"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := collect(t, ln)
+
+	w, err := logging.NewNetWriter("tcp", ln.Addr().String(), logging.WithTLS(&tls.Config{RootCAs: roots}))
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+	defer w.Close()
+	w.Write([]byte("secret"))
+	expectLines(t, lines, "secret")
+
+	if _, err := logging.NewNetWriter("udp", ln.Addr().String(), logging.WithTLS(&tls.Config{})); err == nil {
+		t.Error("expected error for TLS over UDP")
+	}
+}
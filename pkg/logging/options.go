@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/voler88/conslog"
+)
+
+// Option configures optional [Logger] features in [NewLogger].
+type Option func(*config)
+
+// config holds optional settings applied by [NewLogger].
+type config struct {
+	stackLevel       slog.Leveler  // minimum level for stack capture, nil disables it
+	clock            conslog.Clock // source of record timestamps, nil keeps record time
+	filter           *Filter       // rules applied before the handler, nil disables them
+	source           bool          // add the source location of the log call
+	structuredErrors bool          // expand all errors in JSON output
+}
+
+// newConfig applies options to a default configuration.
+func newConfig(options []Option) *config {
+	cfg := new(config)
+	for _, opt := range options {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithStackTrace captures the goroutine stack at the log call for records
+// at or above level. Console output renders it as an indented trace, other
+// handlers receive it as a "stack" attribute holding an array of frames.
+func WithStackTrace(level Level) Option {
+	return func(c *config) {
+		c.stackLevel = level
+	}
+}
+
+// WithClock stamps records with the time of clock instead of the current time,
+// e.g. a fake clock from pkg/logtest for deterministic output in tests.
+// Durations logged by the HTTP middleware, the transport, pkg/sqllog and
+// pkg/rpclog are measured with it as well, see [ClockOf].
+func WithClock(clock conslog.Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// systemClock returns the current time.
+var systemClock conslog.Clock = conslog.ClockFunc(time.Now)
+
+// ClockOf returns the clock set with [WithClock] for l, or the system clock
+// for loggers without one.
+func ClockOf(l Logger) conslog.Clock {
+	if l, ok := l.(*logger); ok && l.clock != nil {
+		return l.clock
+	}
+	return systemClock
+}
+
+// WithSource adds the source location of the log call to records of
+// structured handlers, e.g. "source" in JSON and log.origin in ECS.
+// Console and logfmt output do not show it.
+func WithSource() Option {
+	return func(c *config) {
+		c.source = true
+	}
+}
+
+// WithStructuredErrors expands error values in JSON output into objects with
+// message, attributes, stack trace and causes, see [conslog.ErrorValue].
+// Without it errors are written as their message like [slog.JSONHandler]
+// does, except those returned by [WrapError] which always carry details.
+func WithStructuredErrors() Option {
+	return func(c *config) {
+		c.structuredErrors = true
+	}
+}
+
+// WithFilter applies the rules of f to records before they are written,
+// see [NewFilter] and [LoadFilter].
+func WithFilter(f *Filter) Option {
+	return func(c *config) {
+		c.filter = f
+	}
+}
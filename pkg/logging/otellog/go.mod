@@ -0,0 +1,10 @@
+module github.com/voler88/conslog/pkg/logging/otellog
+
+go 1.24.4
+
+require (
+	github.com/voler88/conslog v0.2.0
+	go.opentelemetry.io/otel/trace v1.35.0
+)
+
+require go.opentelemetry.io/otel v1.35.0 // indirect
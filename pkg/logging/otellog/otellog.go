@@ -0,0 +1,36 @@
+// Package otellog correlates log records with spans of the OpenTelemetry
+// trace API. Importing it registers [Extract] with
+// [logging.RegisterTraceExtractor], so [logging.TraceLogger],
+// [logging.NewTraceHandler] and [logging.AccessLogMiddleware] add the IDs
+// of the active span:
+//
+//	import _ "github.com/voler88/conslog/pkg/logging/otellog"
+//
+// It is a separate module, so that only programs importing it depend on the
+// OpenTelemetry API.
+package otellog
+
+import (
+	"context"
+
+	"github.com/voler88/conslog/pkg/logging"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func init() {
+	logging.RegisterTraceExtractor(Extract)
+}
+
+// Extract returns the trace context of the span in ctx,
+// implements [logging.TraceExtractor].
+func Extract(ctx context.Context) (logging.TraceContext, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return logging.TraceContext{}, false
+	}
+	return logging.TraceContext{
+		TraceID: sc.TraceID(),
+		SpanID:  sc.SpanID(),
+		Flags:   byte(sc.TraceFlags()),
+	}, true
+}
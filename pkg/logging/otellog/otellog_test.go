@@ -0,0 +1,36 @@
+package otellog_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+	"github.com/voler88/conslog/pkg/logging/otellog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestExtract verifies trace attributes of the span in the context.
+func TestExtract(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	tc, ok := otellog.Extract(ctx)
+	if !ok || tc.String() != "00-4bf92f35000000000000000000000000-00f067aa00000000-01" {
+		t.Errorf("unexpected trace context %v, %t", tc, ok)
+	}
+	if _, ok := otellog.Extract(context.Background()); ok {
+		t.Error("expected no trace context without span")
+	}
+
+	var buf bytes.Buffer
+	logging.TraceLogger(ctx, logging.NewLogger(&buf, logging.JSON)).Info("traced")
+	if !strings.Contains(buf.String(), `"trace_id":"4bf92f35000000000000000000000000","span_id":"00f067aa00000000"`) {
+		t.Errorf("expected trace attributes, got: %s", buf.String())
+	}
+}
@@ -0,0 +1,123 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+)
+
+// RecoverOption configures panic handling in [RecoverAndLog], [Go] and [RecoverMiddleware].
+type RecoverOption func(*recoverConfig)
+
+// recoverConfig holds panic handling settings.
+type recoverConfig struct {
+	repanic bool  // panic again with the original value after logging
+	fatal   bool  // exit the process after logging
+	args    []any // additional key-value pairs for the log record
+}
+
+// newRecoverConfig applies options to a default configuration.
+func newRecoverConfig(options []RecoverOption) *recoverConfig {
+	cfg := new(recoverConfig)
+	for _, opt := range options {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithRepanic panics again with the original value after logging,
+// e.g. to let an outer recovery or the runtime handle it.
+func WithRepanic() RecoverOption {
+	return func(c *recoverConfig) {
+		c.repanic = true
+	}
+}
+
+// WithFatal exits the process with status 1 after logging the panic.
+// It takes precedence over [WithRepanic].
+func WithFatal() RecoverOption {
+	return func(c *recoverConfig) {
+		c.fatal = true
+	}
+}
+
+// WithPanicAttrs adds key-value pairs to the panic log record.
+func WithPanicAttrs(args ...any) RecoverOption {
+	return func(c *recoverConfig) {
+		c.args = append(c.args, args...)
+	}
+}
+
+// RecoverAndLog recovers a panic and logs its value and stack trace at Error level.
+// It must be called directly with defer:
+//
+//	defer logging.RecoverAndLog(logger)
+func RecoverAndLog(l Logger, options ...RecoverOption) {
+	if v := recover(); v != nil {
+		handlePanic(l, v, newRecoverConfig(options))
+	}
+}
+
+// Go runs fn in a new goroutine that recovers and logs panics
+// as [RecoverAndLog] does.
+func Go(l Logger, fn func(), options ...RecoverOption) {
+	go func() {
+		defer RecoverAndLog(l, options...)
+		fn()
+	}()
+}
+
+// RecoverMiddleware returns HTTP middleware that recovers panics in the next
+// handler, logs them with the request method and path and responds with
+// 500 Internal Server Error unless the handler already started a response.
+// [http.ErrAbortHandler] is passed through unlogged so the server can abort
+// the response as usual.
+func RecoverMiddleware(l Logger, options ...RecoverOption) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &responseWriter{ResponseWriter: w} // records whether a response was started
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(v)
+				}
+
+				cfg := newRecoverConfig(options)
+				cfg.args = append(cfg.args, "method", r.Method, "path", r.URL.Path)
+				// response is written first, repanic or exit would prevent it
+				if rw.status == 0 {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+				handlePanic(l, v, cfg)
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// handlePanic logs a recovered panic value with the stack of the panicking
+// goroutine and then repanics or exits if configured.
+func handlePanic(l Logger, v any, cfg *recoverConfig) {
+	err, ok := v.(error)
+	if !ok {
+		err = fmt.Errorf("%v", v)
+	}
+
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(3, pcs) // skip runtime.Callers, handlePanic and the deferred caller
+
+	perr := &stackError{msg: "panic", err: err, stack: pcs[:n]}
+	l.Error("recovered from panic", append([]any{"panic", perr}, cfg.args...)...)
+
+	switch {
+	case cfg.fatal:
+		os.Exit(1)
+	case cfg.repanic:
+		panic(v)
+	}
+}
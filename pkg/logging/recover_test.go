@@ -0,0 +1,189 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// notifyWriter forwards each write to a channel.
+type notifyWriter chan string
+
+func (w notifyWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+// panicLine holds fields of a JSON panic log record.
+type panicLine struct {
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+	Panic struct {
+		Msg    string           `json:"msg"`
+		Stack  []map[string]any `json:"stack"`
+		Causes []map[string]any `json:"causes"`
+	} `json:"panic"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Job    string `json:"job"`
+}
+
+// parsePanicLine decodes a JSON panic log record.
+func parsePanicLine(t *testing.T, data string) panicLine {
+	t.Helper()
+	var line panicLine
+	if err := json.Unmarshal([]byte(data), &line); err != nil {
+		t.Fatalf("failed to parse log line: %v: %s", err, data)
+	}
+	return line
+}
+
+// TestRecoverAndLog verifies panic values are logged with a stack at Error level.
+func TestRecoverAndLog(t *testing.T) {
+	tt := []struct {
+		name      string
+		value     any
+		wantCause string
+	}{
+		{"String", "boom", "boom"},
+		{"Error", errors.New("failure"), "failure"},
+		{"Int", 42, "42"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := logging.NewLogger(&buf, logging.JSON)
+
+			func() {
+				defer logging.RecoverAndLog(l, logging.WithPanicAttrs("job", "sync"))
+				panic(tc.value)
+			}()
+
+			line := parsePanicLine(t, buf.String())
+			if line.Level != "ERROR" || line.Msg != "recovered from panic" {
+				t.Errorf("unexpected record: %s", buf.String())
+			}
+			if line.Panic.Msg != "panic: "+tc.wantCause {
+				t.Errorf("expected panic message %q, got %q", "panic: "+tc.wantCause, line.Panic.Msg)
+			}
+			if len(line.Panic.Stack) == 0 {
+				t.Error("expected panic stack")
+			}
+			if line.Job != "sync" {
+				t.Errorf("expected job attribute 'sync', got %q", line.Job)
+			}
+		})
+	}
+
+	t.Run("NoPanic", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := logging.NewLogger(&buf, logging.JSON)
+		func() {
+			defer logging.RecoverAndLog(l)
+		}()
+		if buf.Len() != 0 {
+			t.Errorf("expected no output without panic, got: %s", buf.String())
+		}
+	})
+}
+
+// TestRecoverAndLogRepanic verifies the original value is re-panicked after logging.
+func TestRecoverAndLogRepanic(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("expected re-panic with %q, got %v", "boom", r)
+		}
+		if !strings.Contains(buf.String(), "recovered from panic") {
+			t.Errorf("expected panic to be logged before re-panic, got: %s", buf.String())
+		}
+	}()
+
+	func() {
+		defer logging.RecoverAndLog(l, logging.WithRepanic())
+		panic("boom")
+	}()
+}
+
+// TestGo verifies that panics in goroutines started by [logging.Go] are logged.
+func TestGo(t *testing.T) {
+	w := make(notifyWriter, 1)
+	l := logging.NewLogger(w, logging.JSON)
+
+	logging.Go(l, func() { panic("in goroutine") })
+
+	select {
+	case out := <-w:
+		if line := parsePanicLine(t, out); line.Panic.Msg != "panic: in goroutine" {
+			t.Errorf("unexpected panic record: %s", out)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for panic log")
+	}
+}
+
+// TestRecoverMiddleware verifies that handler panics are logged and answered with 500.
+func TestRecoverMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+
+	h := logging.RecoverMiddleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("handler failed")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+	line := parsePanicLine(t, buf.String())
+	if line.Method != http.MethodPost || line.Path != "/orders" {
+		t.Errorf("expected request attributes, got: %s", buf.String())
+	}
+
+	t.Run("ResponseStarted", func(t *testing.T) {
+		buf.Reset()
+		h := logging.RecoverMiddleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte("partial"))
+			panic("handler failed")
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if rec.Code != http.StatusAccepted || rec.Body.String() != "partial" {
+			t.Errorf("expected started response to be kept, got %d %q", rec.Code, rec.Body.String())
+		}
+		if line := parsePanicLine(t, buf.String()); line.Msg != "recovered from panic" {
+			t.Errorf("expected panic to be logged, got: %s", buf.String())
+		}
+	})
+
+	t.Run("AbortHandler", func(t *testing.T) {
+		buf.Reset()
+		h := logging.RecoverMiddleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Errorf("expected ErrAbortHandler to be re-panicked, got %v", r)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("expected no log for aborted handler, got: %s", buf.String())
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
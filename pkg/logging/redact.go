@@ -0,0 +1,185 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces sensitive values in log output.
+const Redacted = "[REDACTED]"
+
+// defaultRedactKeys lists header, query parameter and JSON field names
+// that are always redacted.
+var defaultRedactKeys = []string{
+	"authorization",
+	"proxy-authorization",
+	"cookie",
+	"set-cookie",
+	"x-api-key",
+	"api_key",
+	"access_token",
+	"refresh_token",
+	"token",
+	"password",
+	"secret",
+}
+
+// redactor masks sensitive header values, query parameters and JSON fields,
+// matching names case-insensitively.
+type redactor struct {
+	keys   map[string]struct{}
+	fields *regexp.Regexp // matches sensitive string fields in partial JSON
+}
+
+// newRedactor returns a [redactor] for the default keys and extra names.
+func newRedactor(extra []string) redactor {
+	names := slices.Concat(defaultRedactKeys, extra)
+	keys := make(map[string]struct{}, len(names))
+	quoted := make([]string, 0, len(names))
+	for _, k := range names {
+		keys[strings.ToLower(k)] = struct{}{}
+		quoted = append(quoted, regexp.QuoteMeta(k))
+	}
+	fields := regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+	return redactor{keys, fields}
+}
+
+// match reports whether name must be redacted.
+func (r redactor) match(name string) bool {
+	_, ok := r.keys[strings.ToLower(name)]
+	return ok
+}
+
+// headerAttrs returns the selected headers as attributes with sensitive
+// values redacted. All headers are returned if names is empty.
+func (r redactor) headerAttrs(h http.Header, names []string) []slog.Attr {
+	if len(names) == 0 {
+		names = make([]string, 0, len(h))
+		for name := range h {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+	}
+
+	attrs := make([]slog.Attr, 0, len(names))
+	for _, name := range names {
+		values := h.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		value := strings.Join(values, ", ")
+		if r.match(name) {
+			value = Redacted
+		}
+		attrs = append(attrs, slog.String(http.CanonicalHeaderKey(name), value))
+	}
+	return attrs
+}
+
+// url returns u as a string with sensitive query parameter values
+// and user password redacted.
+func (r redactor) url(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	u2 := *u
+	if _, ok := u2.User.Password(); ok {
+		u2.User = url.UserPassword(u2.User.Username(), Redacted)
+	}
+	if u2.RawQuery != "" {
+		q := u2.Query()
+		for key := range q {
+			if r.match(key) {
+				q[key] = []string{Redacted}
+			}
+		}
+		u2.RawQuery = q.Encode()
+	}
+	return u2.String()
+}
+
+// body returns a body for logging with sensitive fields redacted.
+// Complete JSON documents are returned as [json.RawMessage], so handlers
+// render them as nested objects, sensitive string fields of invalid or
+// truncated JSON are redacted by pattern and other content is returned
+// as a string.
+func (r redactor) body(data []byte) any {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return r.fields.ReplaceAllString(string(data), `${1}"`+Redacted+`"`)
+	}
+	redacted, err := json.Marshal(r.value(doc))
+	if err != nil {
+		return string(data)
+	}
+	return json.RawMessage(redacted)
+}
+
+// value recursively redacts sensitive fields of a decoded JSON value.
+func (r redactor) value(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if r.match(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = r.value(field)
+			}
+		}
+	case []any:
+		for i, elem := range v {
+			v[i] = r.value(elem)
+		}
+	}
+	return v
+}
+
+// limitedBuffer keeps up to max bytes of written data and
+// reports whether anything was dropped. It is safe for concurrent use,
+// a transport may still send the request body after the response arrived.
+type limitedBuffer struct {
+	mu        sync.Mutex
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+// Write implements [io.Writer], it never fails.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.max - len(b.buf); room < len(p) {
+		b.buf = append(b.buf, p[:max(room, 0)]...)
+		b.truncated = true
+	} else {
+		b.buf = append(b.buf, p...)
+	}
+	return len(p), nil
+}
+
+// Len returns the number of captured bytes.
+func (b *limitedBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.buf)
+}
+
+// bodyAttr returns the captured data as a redacted attribute,
+// truncated bodies are marked with a suffix.
+func (b *limitedBuffer) bodyAttr(key string, r redactor) slog.Attr {
+	b.mu.Lock()
+	data, truncated := slices.Clone(b.buf), b.truncated
+	b.mu.Unlock()
+	if truncated {
+		body := r.fields.ReplaceAllString(string(data), `${1}"`+Redacted+`"`)
+		return slog.String(key, body+"...(truncated)")
+	}
+	return slog.Any(key, r.body(data))
+}
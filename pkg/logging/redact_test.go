@@ -0,0 +1,96 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// TestRedaction verifies that credentials are redacted from captured headers and bodies.
+func TestRedaction(t *testing.T) {
+	tt := []struct {
+		name      string
+		body      string
+		limit     int
+		header    [2]string
+		expect    []string
+		notExpect []string
+	}{
+		{
+			name:      "AuthorizationHeader",
+			header:    [2]string{"Authorization", "Bearer secret-token"},
+			expect:    []string{`"Authorization":"[REDACTED]"`},
+			notExpect: []string{"secret-token"},
+		},
+		{
+			name:      "CustomHeader",
+			header:    [2]string{"X-Session", "s3cr3t"},
+			expect:    []string{`"X-Session":"[REDACTED]"`},
+			notExpect: []string{"s3cr3t"},
+		},
+		{
+			name:      "NestedJSONBody",
+			body:      `{"user":"bob","auth":{"Password":"hunter2"},"items":[{"token":"abc"}]}`,
+			limit:     1024,
+			expect:    []string{`"user":"bob"`, `"Password":"[REDACTED]"`, `"token":"[REDACTED]"`},
+			notExpect: []string{"hunter2", `"abc"`},
+		},
+		{
+			name:      "TruncatedJSONBody",
+			body:      `{"password":"hunter2","padding":"xxxxxxxxxxxxxxxxxxxxxxxx"}`,
+			limit:     32,
+			expect:    []string{`[REDACTED]`, "(truncated)"},
+			notExpect: []string{"hunter2"},
+		},
+		{
+			name:   "PlainBody",
+			body:   "hello world",
+			limit:  1024,
+			expect: []string{`"request_body":"hello world"`},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := logging.NewLogger(&buf, logging.JSON)
+
+			options := []logging.HTTPOption{logging.WithHeaders(), logging.WithRedact("x-session")}
+			if tc.limit > 0 {
+				options = append(options, logging.WithBody(tc.limit))
+			}
+			h := logging.AccessLogMiddleware(l, options...)(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					_, _ = io.Copy(io.Discard, r.Body)
+				},
+			))
+
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tc.body))
+			if tc.header[0] != "" {
+				req.Header.Set(tc.header[0], tc.header[1])
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			out := buf.String()
+			if !json.Valid(bytes.TrimSpace(buf.Bytes())) {
+				t.Fatalf("expected single JSON record, got: %s", out)
+			}
+			for _, want := range tc.expect {
+				if !strings.Contains(out, want) {
+					t.Errorf("expected output to contain %q, got: %s", want, out)
+				}
+			}
+			for _, notWant := range tc.notExpect {
+				if strings.Contains(out, notWant) {
+					t.Errorf("expected output NOT to contain %q, got: %s", notWant, out)
+				}
+			}
+		})
+	}
+}
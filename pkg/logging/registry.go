@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/voler88/conslog"
+)
+
+// HandlerFunc creates a [slog.Handler] writing to w. The handler must
+// respect opts.Level so that [Logger.SetLevel] takes effect.
+type HandlerFunc func(w io.Writer, opts *slog.HandlerOptions) slog.Handler
+
+// ErrHandlerExists is returned when registering a taken handler type name.
+var ErrHandlerExists = errors.New("handler type already registered")
+
+// registry holds the constructors of handler types by name.
+var registry = struct {
+	sync.RWMutex
+	handlers map[HandlerType]HandlerFunc
+}{
+	handlers: map[HandlerType]HandlerFunc{
+		Console: func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+			return conslog.NewConsoleHandler(w, opts)
+		},
+		JSON: func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+			return slog.NewJSONHandler(w, opts)
+		},
+		Text: func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+			return slog.NewTextHandler(w, opts)
+		},
+		Logfmt: func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+			return conslog.NewLogfmtHandler(w, opts)
+		},
+		ECS:  newECSHandler,
+		GELF: newGELFHandler,
+		OTLP: newOTLPHandler,
+		GCP:  newGCPHandler,
+	},
+}
+
+// RegisterHandler makes a handler type available to [NewLogger] by name,
+// typically called from an init function of the package providing it.
+// Returns [ErrHandlerExists] if the name is taken.
+func RegisterHandler(name HandlerType, fn HandlerFunc) error {
+	if name == "" || fn == nil {
+		return errors.New("handler type needs a name and a constructor")
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.handlers[name]; ok {
+		return fmt.Errorf("%w: %q", ErrHandlerExists, name)
+	}
+	registry.handlers[name] = fn
+	return nil
+}
+
+// MustRegisterHandler is like [RegisterHandler] but panics on error.
+func MustRegisterHandler(name HandlerType, fn HandlerFunc) {
+	if err := RegisterHandler(name, fn); err != nil {
+		panic(err)
+	}
+}
+
+// HandlerTypes returns the names of all registered handler types in
+// alphabetical order, e.g. for the help text of a command line flag.
+func HandlerTypes() []HandlerType {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]HandlerType, 0, len(registry.handlers))
+	for name := range registry.handlers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// lookupHandler returns the constructor of a handler type.
+func lookupHandler(name HandlerType) (HandlerFunc, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	fn, ok := registry.handlers[name]
+	return fn, ok
+}
@@ -0,0 +1,82 @@
+package logging_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// TestRegisterHandler verifies custom handler types and duplicate names.
+func TestRegisterHandler(t *testing.T) {
+	const name logging.HandlerType = "test-upper"
+	upper := func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+		return slog.NewTextHandler(upperWriter{w}, opts)
+	}
+
+	if name.IsValid() {
+		t.Fatalf("expected %q to be unknown before registration", name)
+	}
+	if err := logging.RegisterHandler(name, upper); err != nil {
+		t.Fatalf("RegisterHandler failed: %v", err)
+	}
+	if !name.IsValid() || !slices.Contains(logging.HandlerTypes(), name) {
+		t.Errorf("expected %q to be registered, got %v", name, logging.HandlerTypes())
+	}
+
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, name)
+	l.Debug("hidden")
+	l.SetLevel(logging.LevelDebug)
+	l.Debug("shown")
+	if out := buf.String(); strings.Contains(out, "HIDDEN") || !strings.Contains(out, `MSG=SHOWN`) {
+		t.Errorf("expected custom handler with dynamic level, got %q", out)
+	}
+
+	for _, dup := range []logging.HandlerType{name, logging.JSON} {
+		if err := logging.RegisterHandler(dup, upper); !errors.Is(err, logging.ErrHandlerExists) {
+			t.Errorf("expected ErrHandlerExists for %q, got %v", dup, err)
+		}
+	}
+	if err := logging.RegisterHandler("", upper); err == nil {
+		t.Error("expected error for empty name")
+	}
+	if err := logging.RegisterHandler("test-nil", nil); err == nil {
+		t.Error("expected error for nil constructor")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustRegisterHandler to panic on duplicate")
+		}
+	}()
+	logging.MustRegisterHandler(name, upper)
+}
+
+// TestHandlerTypes verifies that built-in types are listed in order.
+func TestHandlerTypes(t *testing.T) {
+	types := logging.HandlerTypes()
+	for _, ht := range []logging.HandlerType{logging.Console, logging.JSON, logging.Text, logging.Logfmt, logging.ECS} {
+		if !slices.Contains(types, ht) {
+			t.Errorf("expected %q in %v", ht, types)
+		}
+	}
+	if !slices.IsSorted(types) {
+		t.Errorf("expected sorted types, got %v", types)
+	}
+}
+
+// upperWriter upper-cases everything written to it.
+type upperWriter struct {
+	w io.Writer
+}
+
+// Write implements [io.Writer] interface.
+func (u upperWriter) Write(p []byte) (int, error) {
+	return u.w.Write(bytes.ToUpper(p))
+}
@@ -0,0 +1,287 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/voler88/conslog"
+)
+
+// schemaRecord is a record prepared for a log schema: attributes are
+// resolved with handler attributes and groups applied, and the first
+// error attribute of the record is moved to Err.
+type schemaRecord struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Source  *slog.Source // nil unless AddSource is set
+	Err     error
+	Attrs   []slog.Attr
+}
+
+// schemaHandler writes records encoded for a log schema such as ECS or
+// syslog, one Write per record. opts.ReplaceAttr is applied to attributes
+// with the names of their groups, not to the fields of the schema such as
+// time and level.
+type schemaHandler struct {
+	opts   slog.HandlerOptions
+	encode func(schemaRecord) ([]byte, error)
+	goas   []groupOrAttrs
+	mu     *sync.Mutex
+	w      io.Writer
+}
+
+// groupOrAttrs is a group or attributes added by WithGroup or WithAttrs.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// newSchemaHandler returns a handler writing records with encode.
+func newSchemaHandler(w io.Writer, opts *slog.HandlerOptions, encode func(schemaRecord) ([]byte, error)) *schemaHandler {
+	h := &schemaHandler{encode: encode, mu: new(sync.Mutex), w: w}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	return h
+}
+
+// Enabled implements [slog.Handler] interface.
+func (h *schemaHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle writes the encoded record, implements [slog.Handler] interface.
+func (h *schemaHandler) Handle(_ context.Context, r slog.Record) error {
+	sr := schemaRecord{Time: r.Time, Level: r.Level, Message: r.Message}
+	if h.opts.AddSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		sr.Source = &slog.Source{Function: f.Function, File: f.File, Line: f.Line}
+	}
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	if h.opts.ReplaceAttr != nil {
+		attrs = h.replaceAttrs(h.groups(), attrs)
+	}
+	attrs = slices.DeleteFunc(attrs, func(a slog.Attr) bool {
+		if err, ok := a.Value.Any().(error); ok && sr.Err == nil {
+			sr.Err = err
+			return true
+		}
+		return false
+	})
+	for i := len(h.goas) - 1; i >= 0; i-- {
+		if g := h.goas[i]; g.group != "" {
+			attrs = []slog.Attr{slog.Group(g.group, attrsToAny(attrs)...)}
+		} else {
+			attrs = append(slices.Clone(g.attrs), attrs...)
+		}
+	}
+	sr.Attrs = resolveAttrs(attrs)
+
+	data, err := h.encode(sr)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.w.Write(data)
+	return err
+}
+
+// WithAttrs implements [slog.Handler] interface.
+func (h *schemaHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	if h.opts.ReplaceAttr != nil {
+		attrs = h.replaceAttrs(h.groups(), attrs)
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+// WithGroup implements [slog.Handler] interface.
+func (h *schemaHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+// with returns a copy of the handler with goa appended.
+func (h *schemaHandler) with(goa groupOrAttrs) *schemaHandler {
+	h2 := *h
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
+
+// groups returns the names of the groups added by WithGroup.
+func (h *schemaHandler) groups() []string {
+	var groups []string
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			groups = append(groups, goa.group)
+		}
+	}
+	return groups
+}
+
+// replaceAttrs resolves attrs and applies opts.ReplaceAttr, which must be
+// set, to those not holding a group. groups are the names of the enclosing
+// groups.
+func (h *schemaHandler) replaceAttrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup {
+			if a.Key != "" {
+				a.Value = slog.GroupValue(h.replaceAttrs(append(slices.Clip(groups), a.Key), a.Value.Group())...)
+			} else {
+				a.Value = slog.GroupValue(h.replaceAttrs(groups, a.Value.Group())...)
+			}
+		} else {
+			a = h.opts.ReplaceAttr(groups, a)
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// jsonLayout returns an encoder of JSON lines holding the value returned
+// by layout.
+func jsonLayout(layout func(schemaRecord) any) func(schemaRecord) ([]byte, error) {
+	return func(r schemaRecord) ([]byte, error) {
+		data, err := json.Marshal(layout(r))
+		return append(data, '\n'), err
+	}
+}
+
+// attrsToAny converts attributes into arguments of [slog.Group].
+func attrsToAny(attrs []slog.Attr) []any {
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return args
+}
+
+// resolveAttrs resolves values, drops empty attributes and groups and
+// inlines groups without a key.
+func resolveAttrs(attrs []slog.Attr) []slog.Attr {
+	var out []slog.Attr
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		switch {
+		case a.Equal(slog.Attr{}):
+		case a.Value.Kind() != slog.KindGroup:
+			out = append(out, a)
+		case a.Key == "":
+			out = append(out, resolveAttrs(a.Value.Group())...)
+		default:
+			if group := resolveAttrs(a.Value.Group()); len(group) > 0 {
+				out = append(out, slog.Attr{Key: a.Key, Value: slog.GroupValue(group...)})
+			}
+		}
+	}
+	return out
+}
+
+// attrsToJSON converts resolved attributes into a JSON object with groups
+// as nested objects, errors expanded by [conslog.ErrorValue] and durations
+// in nanoseconds like [slog.JSONHandler].
+func attrsToJSON(attrs []slog.Attr) map[string]any {
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value; v.Kind() {
+		case slog.KindGroup:
+			m[a.Key] = attrsToJSON(v.Group())
+		case slog.KindDuration:
+			m[a.Key] = int64(v.Duration())
+		case slog.KindAny:
+			if err, ok := v.Any().(error); ok {
+				m[a.Key] = conslog.ErrorValue(err).Any()
+				continue
+			}
+			m[a.Key] = v.Any()
+		default:
+			m[a.Key] = v.Any()
+		}
+	}
+	return m
+}
+
+// scalarString formats a non-group value as a string, complex values
+// as compact JSON.
+func scalarString(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return errorMessage(x)
+		case []byte:
+			return string(x)
+		case interface{ String() string }:
+			if isNilPointer(x) {
+				return "<nil>"
+			}
+			return x.String()
+		}
+		if data, err := json.Marshal(v.Any()); err == nil {
+			return string(data)
+		}
+	}
+	return v.String()
+}
+
+// setPath sets a value in nested JSON objects, creating objects along
+// the path and replacing non-object values in the way.
+func setPath(m map[string]any, v any, path ...string) {
+	for _, key := range path[:len(path)-1] {
+		sub, ok := m[key].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			m[key] = sub
+		}
+		m = sub
+	}
+	m[path[len(path)-1]] = v
+}
+
+// errorStackText returns the stack trace recorded by err or one of its
+// causes in the format of Go panics, empty if there is none.
+func errorStackText(err error) string {
+	var st conslog.StackTracer
+	if isNilPointer(err) || !errors.As(err, &st) || isNilPointer(st) || len(st.StackTrace()) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	frames := runtime.CallersFrames(st.StackTrace())
+	for {
+		f, more := frames.Next()
+		b.WriteString(f.Function + "()\n\t" + f.File + ":" + strconv.Itoa(f.Line) + "\n")
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
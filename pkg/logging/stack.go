@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/voler88/conslog"
+)
+
+// stackHandler wraps a [slog.Handler] and adds the call stack as a top-level
+// "stack" attribute to records at or above the configured level.
+type stackHandler struct {
+	slog.Handler                // base with goas applied
+	base         slog.Handler   // handler the stack is added to
+	level        slog.Leveler   // minimum level for stack capture
+	goas         []groupOrAttrs // added after the stack, if a group is open
+}
+
+// newStackHandler returns a handler adding stacks to records of h.
+func newStackHandler(h slog.Handler, level slog.Leveler) *stackHandler {
+	return &stackHandler{Handler: h, base: h, level: level}
+}
+
+// Handle adds the stack attribute if enabled and passes the record on,
+// implements [slog.Handler] interface.
+func (h *stackHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.level.Level() {
+		return h.Handler.Handle(ctx, r)
+	}
+	stack := slog.Attr{Key: "stack", Value: conslog.StackValue(r.PC)}
+	if !slices.ContainsFunc(h.goas, func(g groupOrAttrs) bool { return g.group != "" }) {
+		r = r.Clone()
+		r.AddAttrs(stack)
+		return h.Handler.Handle(ctx, r)
+	}
+
+	// record attributes go into the open groups, add the stack before them
+	next := h.base.WithAttrs([]slog.Attr{stack})
+	for _, g := range h.goas {
+		if g.group != "" {
+			next = next.WithGroup(g.group)
+		} else {
+			next = next.WithAttrs(g.attrs)
+		}
+	}
+	return next.Handle(ctx, r)
+}
+
+// WithAttrs implements [slog.Handler] interface.
+func (h *stackHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(h.Handler.WithAttrs(attrs), groupOrAttrs{attrs: attrs})
+}
+
+// WithGroup implements [slog.Handler] interface.
+func (h *stackHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(h.Handler.WithGroup(name), groupOrAttrs{group: name})
+}
+
+// with returns a copy of h wrapping next with goa recorded.
+func (h *stackHandler) with(next slog.Handler, goa groupOrAttrs) *stackHandler {
+	h2 := *h
+	h2.Handler = next
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
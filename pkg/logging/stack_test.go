@@ -0,0 +1,96 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// TestWithStackTrace verifies stack capture for each handler type.
+func TestWithStackTrace(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := logging.NewLogger(&buf, logging.JSON, logging.WithStackTrace(logging.LevelError))
+
+		l.Warn("no stack")
+		l.Error("with stack")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 log lines, got %d: %s", len(lines), buf.String())
+		}
+
+		var warn, errLine map[string]any
+		if err := json.Unmarshal([]byte(lines[0]), &warn); err != nil {
+			t.Fatalf("failed to parse log line: %v", err)
+		}
+		if err := json.Unmarshal([]byte(lines[1]), &errLine); err != nil {
+			t.Fatalf("failed to parse log line: %v", err)
+		}
+
+		if _, ok := warn["stack"]; ok {
+			t.Errorf("expected no stack below level, got: %s", lines[0])
+		}
+		frames, ok := errLine["stack"].([]any)
+		if !ok || len(frames) == 0 {
+			t.Fatalf("expected stack array, got: %s", lines[1])
+		}
+		var found bool
+		for _, f := range frames {
+			frame, _ := f.(map[string]any)
+			if fn, _ := frame["function"].(string); strings.HasSuffix(fn, "TestWithStackTrace.func1") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected test function in stack, got: %v", frames)
+		}
+	})
+
+	t.Run("CallerFrame", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := logging.NewLogger(&buf, logging.JSON, logging.WithStackTrace(logging.LevelError))
+
+		l.WithGroup("req").With("id", 1).Error("with stack", "k", "v")
+
+		var line map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+			t.Fatalf("failed to parse log line: %v: %s", err, buf.String())
+		}
+		frames, ok := line["stack"].([]any)
+		if !ok || len(frames) == 0 {
+			t.Fatalf("expected top-level stack array, got: %s", buf.String())
+		}
+		frame, _ := frames[0].(map[string]any)
+		if fn, _ := frame["function"].(string); !strings.HasSuffix(fn, "TestWithStackTrace.func2") {
+			t.Errorf("expected first frame in the calling function, got: %v", frames[0])
+		}
+		group, _ := line["req"].(map[string]any)
+		if _, ok := group["stack"]; ok || group["id"] != float64(1) || group["k"] != "v" {
+			t.Errorf("expected attributes but no stack in group, got: %v", line["req"])
+		}
+	})
+
+	t.Run("Console", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := logging.NewLogger(&buf, logging.Console, logging.WithStackTrace(logging.LevelWarn))
+
+		l.Warn("with stack")
+		if out := buf.String(); !strings.Contains(out, "stack:") || !strings.Contains(out, "TestWithStackTrace.func3()") {
+			t.Errorf("expected rendered stack, got: %s", out)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := logging.NewLogger(&buf, logging.Text)
+
+		l.Error("no stack")
+		if strings.Contains(buf.String(), "stack=") {
+			t.Errorf("expected no stack without option, got: %s", buf.String())
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"strings"
+)
+
+// stdWriter turns messages of a standard library [log.Logger] into records.
+// Prefix, date, time and file flags of the source logger are stripped, the
+// file location is kept as a "file" attribute, apart from the "source"
+// added by [WithSource].
+type stdWriter struct {
+	l      Logger
+	level  Level
+	source *log.Logger // logger whose prefix and flags are stripped
+}
+
+// Write implements [io.Writer], log.Logger calls it once per message.
+func (w *stdWriter) Write(p []byte) (int, error) {
+	prefix, flags := w.source.Prefix(), w.source.Flags()
+
+	msg, file := parseStdMessage(string(bytes.TrimRight(p, "\n")), prefix, flags)
+	if file != "" {
+		LogAt(w.l, w.level, msg, "file", file)
+	} else {
+		LogAt(w.l, w.level, msg)
+	}
+	return len(p), nil
+}
+
+// parseStdMessage strips the prefix and header written by a [log.Logger]
+// with the given prefix and flags, returning the message and file location.
+func parseStdMessage(s, prefix string, flags int) (msg, file string) {
+	if flags&log.Lmsgprefix == 0 {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	if flags&log.Ldate != 0 {
+		s = skipField(s) // 2009/01/23
+	}
+	if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		s = skipField(s) // 01:23:23 or 01:23:23.123123
+	}
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		if i := strings.Index(s, ": "); i >= 0 {
+			file, s = s[:i], s[i+2:]
+		}
+	}
+	if flags&log.Lmsgprefix != 0 {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	return s, file
+}
+
+// skipField removes the leading space separated field of s.
+func skipField(s string) string {
+	if _, rest, ok := strings.Cut(s, " "); ok {
+		return rest
+	}
+	return s
+}
+
+// NewStdLogger returns a standard library [log.Logger] whose messages are
+// logged through l at level. Prefix and flags may still be changed on the
+// returned logger, they are stripped from messages.
+func NewStdLogger(l Logger, level Level) *log.Logger {
+	w := &stdWriter{l: l, level: level}
+	w.source = log.New(w, "", 0)
+	return w.source
+}
+
+// RedirectStdLog sends messages of the standard library default logger,
+// e.g. from [log.Printf] in third-party code, through l at level. Prefix
+// and flags of the default logger are stripped from messages. The returned
+// function restores the previous output.
+func RedirectStdLog(l Logger, level Level) (restore func()) {
+	std := log.Default()
+	prev := std.Writer()
+	std.SetOutput(&stdWriter{l: l, level: level, source: std})
+	return func() {
+		std.SetOutput(prev)
+	}
+}
@@ -0,0 +1,106 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// stdLine holds fields of a JSON record written through the standard library bridge.
+type stdLine struct {
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+	File  string `json:"file"`
+}
+
+// TestNewStdLogger verifies that prefixes and flags are stripped from messages.
+func TestNewStdLogger(t *testing.T) {
+	tt := []struct {
+		name     string
+		prefix   string
+		flags    int
+		wantFile string
+	}{
+		{"NoFlags", "", 0, ""},
+		{"Prefix", "app: ", 0, ""},
+		{"StdFlags", "", log.LstdFlags, ""},
+		{"Microseconds", "[x] ", log.Ldate | log.Lmicroseconds | log.LUTC, ""},
+		{"ShortFile", "", log.LstdFlags | log.Lshortfile, "stdlog_test.go:"},
+		{"LongFileMsgPrefix", "svc: ", log.Ltime | log.Llongfile | log.Lmsgprefix, "/stdlog_test.go:"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := logging.NewLogger(&buf, logging.JSON)
+
+			std := logging.NewStdLogger(l, logging.LevelWarn)
+			std.SetPrefix(tc.prefix)
+			std.SetFlags(tc.flags)
+			std.Printf("disk %s: %d%% used", "sda", 91)
+
+			var line stdLine
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("failed to parse log line: %v: %s", err, buf.String())
+			}
+			if line.Level != "WARN" || line.Msg != "disk sda: 91% used" {
+				t.Errorf("unexpected record: %s", buf.String())
+			}
+			if !strings.Contains(line.File, tc.wantFile) || (tc.wantFile == "") != (line.File == "") {
+				t.Errorf("expected file containing %q, got %q", tc.wantFile, line.File)
+			}
+		})
+	}
+}
+
+// TestStdLoggerWithSource verifies that the file location of the log call
+// does not replace the source location of the record.
+func TestStdLoggerWithSource(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON, logging.WithSource())
+	std := logging.NewStdLogger(l, logging.LevelInfo)
+	std.SetFlags(log.Lshortfile)
+	std.Print("ready")
+
+	var line struct {
+		File   string         `json:"file"`
+		Source map[string]any `json:"source"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("failed to parse log line: %v: %s", err, buf.String())
+	}
+	if !strings.HasPrefix(line.File, "stdlog_test.go:") || line.Source["function"] == nil {
+		t.Errorf("expected file and source location, got: %s", buf.String())
+	}
+}
+
+// TestRedirectStdLog verifies redirection and restoration of the default logger.
+func TestRedirectStdLog(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+
+	restore := logging.RedirectStdLog(l, logging.LevelInfo)
+	log.Print("from third party")
+	restore()
+
+	var line stdLine
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("failed to parse log line: %v: %s", err, buf.String())
+	}
+	if line.Level != "INFO" || line.Msg != "from third party" {
+		t.Errorf("unexpected record: %s", buf.String())
+	}
+
+	buf.Reset()
+	orig := log.Writer()
+	var other bytes.Buffer
+	log.SetOutput(&other)
+	defer log.SetOutput(orig)
+	log.Print("after restore")
+	if buf.Len() != 0 || !strings.Contains(other.String(), "after restore") {
+		t.Errorf("expected no records after restore, got: %s", buf.String())
+	}
+}
@@ -0,0 +1,337 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFormat selects the message format of [NewSyslogHandler].
+type SyslogFormat int
+
+// Syslog message formats.
+const (
+	RFC5424 SyslogFormat = iota // structured syslog with attributes as structured data
+	RFC3164                     // BSD syslog with attributes as key=value pairs
+)
+
+// Facility is a syslog facility, the source of a message.
+type Facility int
+
+// Common syslog facilities.
+const (
+	FacilityUser   Facility = 1
+	FacilityDaemon Facility = 3
+	FacilityAuth   Facility = 4
+	FacilityLocal0 Facility = 16
+	FacilityLocal1 Facility = 17
+	FacilityLocal2 Facility = 18
+	FacilityLocal3 Facility = 19
+	FacilityLocal4 Facility = 20
+	FacilityLocal5 Facility = 21
+	FacilityLocal6 Facility = 22
+	FacilityLocal7 Facility = 23
+)
+
+// DefaultSDID is the structured data ID holding attributes in RFC 5424
+// messages, using the private enterprise number reserved for examples.
+const DefaultSDID = "attrs@32473"
+
+// SyslogOption configures [NewSyslogHandler].
+type SyslogOption func(*syslogConfig)
+
+// syslogConfig holds the header fields of syslog messages.
+type syslogConfig struct {
+	format   SyslogFormat
+	facility Facility
+	hostname string
+	appName  string
+	procID   string
+	sdID     string
+}
+
+// WithSyslogFormat selects the message format, [RFC5424] by default.
+func WithSyslogFormat(f SyslogFormat) SyslogOption {
+	return func(c *syslogConfig) {
+		c.format = f
+	}
+}
+
+// WithFacility sets the facility of messages, [FacilityUser] by default.
+func WithFacility(f Facility) SyslogOption {
+	return func(c *syslogConfig) {
+		c.facility = f
+	}
+}
+
+// WithHostname sets the host name of messages, the name reported by the
+// kernel by default.
+func WithHostname(name string) SyslogOption {
+	return func(c *syslogConfig) {
+		c.hostname = name
+	}
+}
+
+// WithAppName sets the application name of messages, the program name
+// by default.
+func WithAppName(name string) SyslogOption {
+	return func(c *syslogConfig) {
+		c.appName = name
+	}
+}
+
+// WithSDID sets the structured data ID of RFC 5424 attributes,
+// [DefaultSDID] by default.
+func WithSDID(id string) SyslogOption {
+	return func(c *syslogConfig) {
+		c.sdID = id
+	}
+}
+
+// NewSyslogHandler returns a handler formatting records as syslog messages,
+// one Write per message without trailing newline, e.g. to a [SyslogWriter].
+// Levels map to severities: Debug is debug, Info informational, levels
+// between Info and Warn notice, Warn warning, Error error and Error+4 critical.
+func NewSyslogHandler(w io.Writer, opts *slog.HandlerOptions, options ...SyslogOption) slog.Handler {
+	c := &syslogConfig{
+		facility: FacilityUser,
+		appName:  filepath.Base(os.Args[0]),
+		procID:   strconv.Itoa(os.Getpid()),
+		sdID:     DefaultSDID,
+	}
+	c.hostname, _ = os.Hostname()
+	for _, opt := range options {
+		opt(c)
+	}
+
+	if c.format == RFC3164 {
+		return newSchemaHandler(w, opts, c.encodeRFC3164)
+	}
+	return newSchemaHandler(w, opts, c.encodeRFC5424)
+}
+
+// syslogParams returns the record attributes as flat key-value pairs, with
+// the error and source location of the record.
+func syslogParams(r schemaRecord) []flatAttr {
+	params := flattenAttrs(nil, "", r.Attrs)
+	if r.Err != nil {
+		params = append(params, flatAttr{"error", slog.StringValue(errorMessage(r.Err))})
+	}
+	if r.Source != nil {
+		params = append(params, flatAttr{"source", slog.StringValue(r.Source.File + ":" + strconv.Itoa(r.Source.Line))})
+	}
+	return params
+}
+
+// encodeRFC5424 formats a record as an RFC 5424 message:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID PARAM="value"...] MSG
+func (c *syslogConfig) encodeRFC5424(r schemaRecord) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("<" + strconv.Itoa(int(c.facility)*8+syslogSeverity(r.Level)) + ">1 ")
+	if r.Time.IsZero() {
+		b.WriteString("-")
+	} else {
+		b.WriteString(r.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+	}
+	for _, field := range []struct {
+		v     string
+		limit int
+	}{{c.hostname, 255}, {c.appName, 48}, {c.procID, 128}, {"", 32}} {
+		b.WriteString(" " + syslogHeaderField(field.v, field.limit))
+	}
+
+	b.WriteByte(' ')
+	if params := syslogParams(r); len(params) > 0 {
+		b.WriteString("[" + c.sdID)
+		for _, p := range params {
+			b.WriteString(" " + syslogParamName(p.path) + `="`)
+			syslogParamEscaper.WriteString(&b, scalarString(p.value))
+			b.WriteString(`"`)
+		}
+		b.WriteString("]")
+	} else {
+		b.WriteString("-")
+	}
+
+	if r.Message != "" {
+		b.WriteString(" " + r.Message)
+	}
+	return []byte(b.String()), nil
+}
+
+// encodeRFC3164 formats a record as a BSD syslog message with attributes
+// appended to the message: <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG key=value
+func (c *syslogConfig) encodeRFC3164(r schemaRecord) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("<" + strconv.Itoa(int(c.facility)*8+syslogSeverity(r.Level)) + ">")
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	b.WriteString(t.Format(time.Stamp) + " " + syslogHeaderField(c.hostname, 255) + " ")
+	b.WriteString(syslogHeaderField(c.appName, 32) + "[" + c.procID + "]: " + r.Message)
+	for _, p := range syslogParams(r) {
+		b.WriteString(" " + p.path + "=")
+		if v := scalarString(p.value); v == "" || strings.ContainsAny(v, " \"=") {
+			b.WriteString(strconv.Quote(v))
+		} else {
+			b.WriteString(v)
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+// syslogHeaderField returns a header field of printable ASCII without spaces,
+// truncated to limit characters, "-" if empty.
+func syslogHeaderField(s string, limit int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+	return s[:min(len(s), limit)]
+}
+
+// syslogParamName returns a valid structured data parameter name: printable
+// ASCII except '=', ' ', ']' and '"', at most 32 characters.
+func syslogParamName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "_"
+	}
+	return s[:min(len(s), 32)]
+}
+
+// syslogParamEscaper escapes the characters RFC 5424 requires in parameter values.
+var syslogParamEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
+
+// localSyslogPaths are the usual sockets of the local syslog daemon.
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogWriter sends syslog messages to a server, one message per Write.
+// Messages over stream connections are framed by octet counting (RFC 6587).
+// A failed write reconnects and is retried once if no part of the message was
+// sent, a partly sent frame is not repeated. It is safe for concurrent use.
+type SyslogWriter struct {
+	network string
+	addr    string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	stream bool // octet counting framing
+	closed bool
+}
+
+// DialSyslog connects to a syslog server. Network is "udp", "tcp", "unix"
+// or "unixgram", "unix" tries a datagram socket first like /dev/log.
+// An empty network and address connect to the local syslog daemon.
+func DialSyslog(network, addr string) (*SyslogWriter, error) {
+	w := &SyslogWriter{network: network, addr: addr}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// connect opens a connection, closing the previous one.
+func (w *SyslogWriter) connect() error {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	var candidates [][2]string // network and address pairs to try
+	switch {
+	case w.network == "" && w.addr == "":
+		for _, path := range localSyslogPaths {
+			candidates = append(candidates, [2]string{"unixgram", path}, [2]string{"unix", path})
+		}
+	case w.network == "unix":
+		candidates = [][2]string{{"unixgram", w.addr}, {"unix", w.addr}}
+	default:
+		candidates = [][2]string{{w.network, w.addr}}
+	}
+
+	var errs []error
+	for _, c := range candidates {
+		conn, err := net.DialTimeout(c[0], c[1], 5*time.Second)
+		if err == nil {
+			w.conn = conn
+			w.stream = c[0] == "tcp" || c[0] == "tcp4" || c[0] == "tcp6" || c[0] == "unix"
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Write sends p as one message, implements [io.Writer] interface.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	if w.conn != nil {
+		n, err := w.send(p)
+		if err == nil {
+			return len(p), nil
+		}
+		if n > 0 {
+			// the server may have received part of the frame, drop the
+			// connection instead of sending the message twice
+			w.conn.Close()
+			w.conn = nil
+			return 0, err
+		}
+	}
+
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+	if _, err := w.send(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// send writes a message to the connection, framed on streams, and returns
+// the number of bytes written.
+func (w *SyslogWriter) send(p []byte) (int, error) {
+	if w.stream {
+		return fmt.Fprintf(w.conn, "%d %s", len(p), p)
+	}
+	return w.conn.Write(p)
+}
+
+// Close closes the connection, later writes return [os.ErrClosed].
+// Implements [io.Closer] interface.
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
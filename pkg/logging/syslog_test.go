@@ -0,0 +1,323 @@
+package logging_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// messageWriter collects each Write as a message.
+type messageWriter struct {
+	msgs []string
+}
+
+// Write implements [io.Writer] interface.
+func (w *messageWriter) Write(p []byte) (int, error) {
+	w.msgs = append(w.msgs, string(p))
+	return len(p), nil
+}
+
+// TestSyslogFormats verifies RFC 5424 and RFC 3164 messages.
+func TestSyslogFormats(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6_000_000, time.UTC)
+	pid := strconv.Itoa(os.Getpid())
+
+	tests := []struct {
+		name    string
+		options []logging.SyslogOption
+		level   slog.Level
+		attrs   []slog.Attr
+		want    string
+	}{
+		{
+			"RFC5424",
+			nil,
+			slog.LevelWarn,
+			[]slog.Attr{
+				slog.Group("req", slog.Int("id", 7)),
+				slog.String("note", `a "b" c]`),
+				slog.Any("err", errors.New("refused")),
+			},
+			`<12>1 2024-01-02T03:04:05.006000Z host app ` + pid + ` - [attrs@32473 req.id="7" note="a \"b\" c\]" error="refused"] slow request`,
+		},
+		{
+			"NilError",
+			nil,
+			slog.LevelInfo,
+			[]slog.Attr{slog.Any("err", (*os.PathError)(nil))},
+			`<14>1 2024-01-02T03:04:05.006000Z host app ` + pid + ` - [attrs@32473 error="<nil>"] slow request`,
+		},
+		{
+			"NoAttrs",
+			[]logging.SyslogOption{logging.WithFacility(logging.FacilityLocal0), logging.WithSDID("x@1")},
+			slog.LevelError + 4,
+			nil,
+			`<130>1 2024-01-02T03:04:05.006000Z host app ` + pid + ` - - slow request`,
+		},
+		{
+			"RFC3164",
+			[]logging.SyslogOption{logging.WithSyslogFormat(logging.RFC3164), logging.WithFacility(logging.FacilityDaemon)},
+			slog.LevelDebug,
+			[]slog.Attr{slog.String("path", "/a b"), slog.Int("n", 1)},
+			`<31>Jan  2 03:04:05 host app[` + pid + `]: slow request path="/a b" n=1`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var w messageWriter
+			options := append([]logging.SyslogOption{logging.WithHostname("host"), logging.WithAppName("app")}, tc.options...)
+			h := logging.NewSyslogHandler(&w, &slog.HandlerOptions{Level: slog.LevelDebug}, options...)
+
+			r := slog.NewRecord(ts, tc.level, "slow request", 0)
+			r.AddAttrs(tc.attrs...)
+			if err := h.Handle(context.Background(), r); err != nil {
+				t.Fatalf("Handle failed: %v", err)
+			}
+			if len(w.msgs) != 1 || w.msgs[0] != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, w.msgs)
+			}
+		})
+	}
+}
+
+// TestSyslogReplaceAttr verifies that ReplaceAttr is applied to attributes
+// with the names of their groups.
+func TestSyslogReplaceAttr(t *testing.T) {
+	var w messageWriter
+	var seen []string
+	opts := &slog.HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		seen = append(seen, strings.Join(append(groups, a.Key), "."))
+		switch a.Key {
+		case "password":
+			a.Value = slog.StringValue("***")
+		case "err":
+			return slog.Attr{}
+		}
+		return a
+	}}
+	h := logging.NewSyslogHandler(&w, opts, logging.WithHostname("host"), logging.WithAppName("app"))
+	l := slog.New(h).With("app", "api").WithGroup("req")
+	l.Info("login", "user", "bob", "password", "secret", "err", errors.New("refused"), slog.Group("tls", "version", "1.3"))
+
+	if len(w.msgs) != 1 || !strings.HasSuffix(w.msgs[0], `[attrs@32473 app="api" req.user="bob" req.password="***" req.tls.version="1.3"] login`) {
+		t.Errorf("expected replaced attributes, got %q", w.msgs)
+	}
+	want := []string{"app", "req.user", "req.password", "req.err", "req.tls.version"}
+	if !slices.Equal(seen, want) {
+		t.Errorf("expected ReplaceAttr calls %v, got %v", want, seen)
+	}
+}
+
+// TestSyslogSeverities verifies the mapping of levels to severities.
+func TestSyslogSeverities(t *testing.T) {
+	levels := map[slog.Level]int{
+		slog.LevelDebug - 4: 7, slog.LevelDebug: 7, slog.LevelInfo: 6, slog.LevelInfo + 2: 5,
+		slog.LevelWarn: 4, slog.LevelError: 3, slog.LevelError + 4: 2, slog.LevelError + 8: 1, slog.LevelError + 12: 0,
+	}
+	for level, severity := range levels {
+		var w messageWriter
+		h := logging.NewSyslogHandler(&w, &slog.HandlerOptions{Level: slog.LevelDebug - 4}, logging.WithFacility(0))
+		if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, level, "m", 0)); err != nil {
+			t.Fatal(err)
+		}
+		if want := "<" + strconv.Itoa(severity) + ">1 - "; !strings.HasPrefix(w.msgs[0], want) {
+			t.Errorf("level %v: expected prefix %q, got %q", level, want, w.msgs[0])
+		}
+	}
+}
+
+// TestSyslogWriterDatagram verifies UDP and unix datagram delivery.
+func TestSyslogWriterDatagram(t *testing.T) {
+	dir, err := os.MkdirTemp("", "syslog") // short path for the socket name limit
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	tests := []struct {
+		name, listenNet, listenAddr, dialNet string
+	}{
+		{"UDP", "udp", "127.0.0.1:0", "udp"},
+		{"Unix", "unixgram", filepath.Join(dir, "log.sock"), "unix"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pc, err := net.ListenPacket(tc.listenNet, tc.listenAddr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer pc.Close()
+
+			w, err := logging.DialSyslog(tc.dialNet, pc.LocalAddr().String())
+			if err != nil {
+				t.Fatalf("DialSyslog failed: %v", err)
+			}
+			defer w.Close()
+
+			l := logging.NewFromHandler(logging.NewSyslogHandler(w, nil, logging.WithHostname("h")), nil)
+			l.Info("hello", "k", "v")
+
+			buf := make([]byte, 1024)
+			pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+			n, _, err := pc.ReadFrom(buf)
+			if err != nil {
+				t.Fatalf("no message received: %v", err)
+			}
+			if msg := string(buf[:n]); !strings.HasPrefix(msg, "<14>1 ") || !strings.HasSuffix(msg, ` [attrs@32473 k="v"] hello`) {
+				t.Errorf("unexpected message %q", msg)
+			}
+		})
+	}
+}
+
+// TestSyslogWriterReconnect verifies octet counting over TCP and
+// reconnecting after the server closed the connection.
+func TestSyslogWriterReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	msgs := make(chan string, 16)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			// read one octet counted message per connection, then drop it
+			br := bufio.NewReader(conn)
+			size, err := br.ReadString(' ')
+			n, _ := strconv.Atoi(strings.TrimSpace(size))
+			if err == nil && n > 0 {
+				msg := make([]byte, n)
+				if _, err := io.ReadFull(br, msg); err == nil {
+					msgs <- string(msg)
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	w, err := logging.DialSyslog("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("DialSyslog failed: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("first message")); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-msgs; got != "first message" {
+		t.Errorf("expected first message, got %q", got)
+	}
+
+	// writes to the dropped connection fail eventually and reconnect
+	deadline := time.After(5 * time.Second)
+	for {
+		w.Write([]byte("second message"))
+		select {
+		case got := <-msgs:
+			if got != "second message" {
+				t.Errorf("expected second message, got %q", got)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+				t.Errorf("expected os.ErrClosed after Close, got %v", err)
+			}
+			return
+		case <-deadline:
+			t.Fatal("no message after reconnect")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+// TestSyslogWriterPartialWrite verifies that a frame partly sent before the
+// server reset the connection is not sent again on a new connection.
+func TestSyslogWriterPartialWrite(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	conns := make(chan int, 4)
+	frames := make(chan string, 4)
+	go func() {
+		for i := 0; ; i++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- i
+			if i == 0 {
+				// read the start of the first frame, then reset the connection
+				_, _ = io.ReadFull(conn, make([]byte, 1024))
+				_ = conn.(*net.TCPConn).SetLinger(0)
+				conn.Close()
+				continue
+			}
+			go func() {
+				defer conn.Close()
+				br := bufio.NewReader(conn)
+				for {
+					size, err := br.ReadString(' ')
+					if err != nil {
+						return
+					}
+					n, _ := strconv.Atoi(strings.TrimSpace(size))
+					msg := make([]byte, n)
+					if _, err := io.ReadFull(br, msg); err != nil {
+						return
+					}
+					frames <- string(msg)
+				}
+			}()
+		}
+	}()
+
+	w, err := logging.DialSyslog("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("DialSyslog failed: %v", err)
+	}
+	defer w.Close()
+	<-conns
+
+	large := strings.Repeat("x", 16<<20) // larger than the socket buffers
+	if _, err := w.Write([]byte(large)); err == nil {
+		t.Fatal("expected error for a frame interrupted by a reset")
+	}
+	select {
+	case <-conns:
+		t.Fatal("expected no reconnect to resend the partly sent frame")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := w.Write([]byte("after reset")); err != nil {
+		t.Fatalf("expected write on a new connection, got %v", err)
+	}
+	select {
+	case got := <-frames:
+		if got != "after reset" {
+			t.Errorf("expected only the next message on the new connection, got %d bytes", len(got))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message after reconnect")
+	}
+}
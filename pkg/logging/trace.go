@@ -0,0 +1,214 @@
+package logging
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/voler88/conslog"
+)
+
+// Attribute keys of the trace context, [conslog.ConsoleHandler] renders the
+// trace ID shortened next to the level.
+const (
+	TraceIDKey = conslog.TraceIDKey
+	SpanIDKey  = "span_id"
+)
+
+// TraceparentHeader is the W3C Trace Context header carrying a [TraceContext].
+const TraceparentHeader = "traceparent"
+
+// ErrTraceparent is returned for malformed traceparent values.
+var ErrTraceparent = errors.New("invalid traceparent")
+
+// TraceContext identifies a span of a distributed trace as defined by
+// W3C Trace Context.
+type TraceContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte // trace flags, bit 0 marks sampled traces
+}
+
+// ParseTraceparent parses a traceparent value such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+// Values of future versions are accepted if they start with the
+// fields of version 00.
+func ParseTraceparent(s string) (TraceContext, error) {
+	var tc TraceContext
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return tc, ErrTraceparent
+	}
+	var version [1]byte
+	if !decodeLowerHex(version[:], s[:2]) || version[0] == 0xff {
+		return tc, ErrTraceparent
+	}
+	if version[0] == 0 && len(s) != 55 || version[0] > 0 && len(s) > 55 && s[55] != '-' {
+		return tc, ErrTraceparent
+	}
+
+	var flags [1]byte
+	if !decodeLowerHex(tc.TraceID[:], s[3:35]) || !decodeLowerHex(tc.SpanID[:], s[36:52]) ||
+		!decodeLowerHex(flags[:], s[53:55]) {
+		return tc, ErrTraceparent
+	}
+	tc.Flags = flags[0]
+	if !tc.IsValid() {
+		return tc, ErrTraceparent
+	}
+	return tc, nil
+}
+
+// decodeLowerHex decodes lowercase hex digits of s into dst.
+func decodeLowerHex(dst []byte, s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// IsValid reports whether trace and span ID are set, all-zero IDs are invalid.
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID != [16]byte{} && tc.SpanID != [8]byte{}
+}
+
+// Sampled reports whether the sampled flag is set.
+func (tc TraceContext) Sampled() bool {
+	return tc.Flags&1 != 0
+}
+
+// String returns the traceparent value of version 00,
+// implements [fmt.Stringer] interface.
+func (tc TraceContext) String() string {
+	b := make([]byte, 0, 55)
+	b = append(b, "00-"...)
+	b = hex.AppendEncode(b, tc.TraceID[:])
+	b = append(b, '-')
+	b = hex.AppendEncode(b, tc.SpanID[:])
+	b = append(b, '-')
+	b = hex.AppendEncode(b, []byte{tc.Flags})
+	return string(b)
+}
+
+// Attrs returns the trace and span ID as attributes in hex encoding.
+func (tc TraceContext) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String(TraceIDKey, hex.EncodeToString(tc.TraceID[:])),
+		slog.String(SpanIDKey, hex.EncodeToString(tc.SpanID[:])),
+	}
+}
+
+// traceKey is the context key for the [TraceContext].
+type traceKey struct{}
+
+// ContextWithTrace returns a copy of ctx that carries tc.
+func ContextWithTrace(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, tc)
+}
+
+// TraceFromContext returns the [TraceContext] stored in ctx by
+// [ContextWithTrace] or [AccessLogMiddleware].
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceKey{}).(TraceContext)
+	return tc, ok && tc.IsValid()
+}
+
+// TraceExtractor returns the trace context of the current span in ctx,
+// e.g. from a tracing library.
+type TraceExtractor func(ctx context.Context) (TraceContext, bool)
+
+// extractors holds the trace extractors in registration order.
+var extractors = struct {
+	sync.RWMutex
+	fns []TraceExtractor
+}{
+	fns: []TraceExtractor{TraceFromContext},
+}
+
+// RegisterTraceExtractor adds a source of trace contexts to [ExtractTrace],
+// typically called from an init function of an adapter package such as
+// pkg/logging/otellog. Extractors are tried after [TraceFromContext] in
+// registration order.
+func RegisterTraceExtractor(fn TraceExtractor) {
+	if fn == nil {
+		return
+	}
+	extractors.Lock()
+	defer extractors.Unlock()
+	extractors.fns = append(extractors.fns, fn)
+}
+
+// ExtractTrace returns the trace context in ctx of the first registered
+// extractor that finds a valid one.
+func ExtractTrace(ctx context.Context) (TraceContext, bool) {
+	extractors.RLock()
+	defer extractors.RUnlock()
+	for _, fn := range extractors.fns {
+		if tc, ok := fn(ctx); ok && tc.IsValid() {
+			return tc, true
+		}
+	}
+	return TraceContext{}, false
+}
+
+// TraceAttrs returns the trace and span ID attributes of the trace context
+// in ctx, nil if there is none.
+func TraceAttrs(ctx context.Context) []slog.Attr {
+	if tc, ok := ExtractTrace(ctx); ok {
+		return tc.Attrs()
+	}
+	return nil
+}
+
+// TraceLogger returns a child of l with the trace and span ID of the trace
+// context in ctx, or l if there is none. [Logger] methods take no context,
+// derive the logger once per request or span.
+func TraceLogger(ctx context.Context, l Logger) Logger {
+	attrs := TraceAttrs(ctx)
+	if attrs == nil {
+		return l
+	}
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return l.With(args...)
+}
+
+// NewTraceHandler returns a handler adding the trace and span ID of the
+// trace context passed to Handle to each record, for use with the context
+// methods of [slog.Logger] such as InfoContext. The attributes are added
+// inside the groups opened with WithGroup.
+func NewTraceHandler(h slog.Handler) slog.Handler {
+	return &traceHandler{h}
+}
+
+// traceHandler wraps a [slog.Handler] and adds trace attributes from the
+// record context.
+type traceHandler struct {
+	slog.Handler
+}
+
+// Handle adds the trace attributes and passes the record on,
+// implements [slog.Handler] interface.
+func (h *traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := TraceAttrs(ctx); attrs != nil {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements [slog.Handler] interface.
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements [slog.Handler] interface.
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{h.Handler.WithGroup(name)}
+}
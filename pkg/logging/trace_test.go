@@ -0,0 +1,102 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// TestParseTraceparent verifies parsing and formatting of traceparent values.
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string // formatted trace context, empty for invalid values
+	}{
+		{"Sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{"NotSampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		{"FutureVersion", "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{"VersionFF", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+		{"TrailingData", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-x", ""},
+		{"FutureNoDash", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01x", ""},
+		{"Uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", ""},
+		{"ZeroTraceID", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", ""},
+		{"ZeroSpanID", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", ""},
+		{"Short", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", ""},
+		{"Empty", "", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := logging.ParseTraceparent(tc.value)
+			if tc.want == "" {
+				if !errors.Is(err, logging.ErrTraceparent) {
+					t.Errorf("expected ErrTraceparent, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTraceparent failed: %v", err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+// TestTraceLogger verifies trace attributes of loggers derived from a context.
+func TestTraceLogger(t *testing.T) {
+	tc, err := logging.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+
+	logging.TraceLogger(context.Background(), l).Info("untraced")
+	logging.TraceLogger(logging.ContextWithTrace(context.Background(), tc), l).Info("traced")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if strings.Contains(lines[0], "trace_id") {
+		t.Errorf("expected no trace attributes, got: %s", lines[0])
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || rec["span_id"] != "00f067aa0ba902b7" {
+		t.Errorf("expected trace attributes, got: %s", lines[1])
+	}
+}
+
+// spanKey is the context key of the span of a fake tracing library.
+type spanKey struct{}
+
+// TestTraceHandler verifies trace attributes from the record context,
+// including a registered extractor.
+func TestTraceHandler(t *testing.T) {
+	tc := logging.TraceContext{TraceID: [16]byte{1}, SpanID: [8]byte{2}}
+	logging.RegisterTraceExtractor(func(ctx context.Context) (logging.TraceContext, bool) {
+		tc, ok := ctx.Value(spanKey{}).(logging.TraceContext)
+		return tc, ok
+	})
+
+	var buf bytes.Buffer
+	l := slog.New(logging.NewTraceHandler(slog.NewJSONHandler(&buf, nil)))
+	l.InfoContext(context.WithValue(context.Background(), spanKey{}, tc), "traced")
+	l.InfoContext(context.Background(), "untraced")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.Contains(lines[0], `"trace_id":"01000000000000000000000000000000","span_id":"0200000000000000"`) {
+		t.Errorf("expected trace attributes, got: %s", lines[0])
+	}
+	if strings.Contains(lines[1], "trace_id") {
+		t.Errorf("expected no trace attributes, got: %s", lines[1])
+	}
+}
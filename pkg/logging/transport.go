@@ -0,0 +1,253 @@
+package logging
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/voler88/conslog"
+)
+
+// defaultDumpLimit is the number of body bytes dumped at Debug level
+// when [WithBody] is not used.
+const defaultDumpLimit = 4096
+
+// WithRetry retries failed idempotent client requests up to n times,
+// waiting backoff multiplied by the attempt number between them.
+// Requests are retried on transport errors and 429, 502, 503 and 504
+// responses. Only used by [NewTransport].
+func WithRetry(n int, backoff time.Duration) HTTPOption {
+	return func(c *httpConfig) {
+		c.maxRetries = n
+		c.retryBackoff = backoff
+	}
+}
+
+// transport is a [http.RoundTripper] that logs outgoing requests.
+type transport struct {
+	base   http.RoundTripper
+	logger Logger
+	clock  conslog.Clock
+	cfg    *httpConfig
+	red    redactor
+}
+
+// NewTransport returns a [http.RoundTripper] that logs each outgoing request
+// with its method, redacted URL, status, latency and number of retries through l.
+// Responses are logged at levels by status class like [AccessLogMiddleware]
+// and transport errors at Error level. When l is enabled for Debug level,
+// request and response headers and bodies are included as well. Responses
+// with a body are then logged once the caller closed it, capturing the body
+// as it is read, so streaming responses are not delayed.
+// If base is nil, [http.DefaultTransport] is used.
+func NewTransport(l Logger, base http.RoundTripper, options ...HTTPOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	cfg := newHTTPConfig(options)
+	return &transport{
+		base:   base,
+		logger: l,
+		clock:  ClockOf(l),
+		cfg:    cfg,
+		red:    newRedactor(cfg.redactKeys),
+	}
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for _, skip := range t.cfg.skip {
+		if skip(req) {
+			return t.base.RoundTrip(req)
+		}
+	}
+
+	dump := t.logger.Enabled(LevelDebug) || t.cfg.bodyLimit > 0
+	limit := t.cfg.bodyLimit
+	if limit <= 0 {
+		limit = defaultDumpLimit
+	}
+
+	start := t.clock.Now()
+	var (
+		resp    *http.Response
+		err     error
+		reqBody *limitedBuffer
+		retries int
+	)
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			if attemptReq, err = t.retryRequest(req, attempt); err != nil {
+				break
+			}
+		}
+		if dump && attemptReq.Body != nil && attemptReq.Body != http.NoBody {
+			if attemptReq == req {
+				attemptReq = shallowCopy(req) // round trippers must not modify the request
+			}
+			reqBody = &limitedBuffer{max: limit}
+			attemptReq.Body = readCloser{io.TeeReader(attemptReq.Body, reqBody), attemptReq.Body}
+		}
+
+		resp, err = t.base.RoundTrip(attemptReq)
+		if attempt >= t.cfg.maxRetries || !t.canRetry(req, resp, err) {
+			break
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, defaultDumpLimit))
+			_ = resp.Body.Close()
+		}
+		retries++
+	}
+
+	args := []any{
+		"method", req.Method,
+		"url", t.red.url(req.URL),
+	}
+	if resp != nil {
+		args = append(args, "status", resp.StatusCode)
+	}
+	args = append(args, "duration", t.clock.Now().Sub(start))
+	if retries > 0 {
+		args = append(args, "retries", retries)
+	}
+
+	if dump || t.cfg.captureHeaders {
+		args = append(args, slog.Attr{
+			Key:   "request_headers",
+			Value: slog.GroupValue(t.red.headerAttrs(req.Header, t.cfg.headers)...),
+		})
+	}
+	if dump && reqBody != nil {
+		args = append(args, reqBody.bodyAttr("request_body", t.red))
+	}
+
+	if err != nil {
+		args = append(args, "error", err)
+		t.logger.Error("http client request", args...)
+		return nil, err
+	}
+
+	if dump || t.cfg.captureHeaders {
+		args = append(args, slog.Attr{
+			Key:   "response_headers",
+			Value: slog.GroupValue(t.red.headerAttrs(resp.Header, t.cfg.headers)...),
+		})
+	}
+	level := t.cfg.levels[statusClass(resp.StatusCode)]
+	if dump && resp.Body != nil && resp.Body != http.NoBody &&
+		resp.StatusCode != http.StatusSwitchingProtocols { // upgraded bodies must stay writable
+		respBody := &limitedBuffer{max: limit}
+		resp.Body = &loggedBody{body: resp.Body, buf: respBody, done: func(err error) {
+			if err != nil {
+				args = append(args, "response_body_error", err)
+			}
+			if respBody.Len() > 0 {
+				args = append(args, respBody.bodyAttr("response_body", t.red))
+			}
+			LogAt(t.logger, level, "http client request", args...)
+		}}
+		return resp, nil
+	}
+
+	LogAt(t.logger, level, "http client request", args...)
+	return resp, nil
+}
+
+// canRetry reports whether a failed attempt of req may be retried.
+func (t *transport) canRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false // canceled or timed out
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false // body cannot be replayed
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+	default:
+		if req.Header.Get("Idempotency-Key") == "" {
+			return false
+		}
+	}
+
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryRequest waits for the backoff delay and returns a copy of req
+// with a fresh body for the given attempt.
+func (t *transport) retryRequest(req *http.Request, attempt int) (*http.Request, error) {
+	timer := time.NewTimer(t.cfg.retryBackoff * time.Duration(attempt))
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case <-timer.C:
+	}
+
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// shallowCopy returns a shallow copy of req.
+func shallowCopy(req *http.Request) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	return r
+}
+
+// loggedBody captures a response body as the caller reads it and calls
+// done with the first read error other than EOF when it is closed.
+type loggedBody struct {
+	body io.ReadCloser
+	buf  *limitedBuffer
+	done func(err error)
+
+	mu     sync.Mutex // Close may be called during Read
+	err    error
+	closed bool
+}
+
+// Read implements [io.Reader].
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.mu.Lock()
+	_, _ = b.buf.Write(p[:n])
+	if err != nil && !errors.Is(err, io.EOF) && b.err == nil {
+		b.err = err
+	}
+	b.mu.Unlock()
+	return n, err
+}
+
+// Close closes the body and logs the request, implements [io.Closer].
+func (b *loggedBody) Close() error {
+	err := b.body.Close()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		b.done(b.err)
+	}
+	return err
+}
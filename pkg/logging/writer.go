@@ -0,0 +1,195 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// defaultMaxLineLength is the number of buffered bytes after which
+// a partial line is emitted as a record.
+const defaultMaxLineLength = 64 * 1024
+
+// levelNamesRe matches the level names of [levelNames].
+const levelNamesRe = `(trace|debug|info|notice|warn|warning|error|err|fatal|panic|critical|crit)`
+
+// levelPrefixRe matches level prefixes such as "ERROR:" or "[warn]". Names
+// followed by a space only are not prefixes, e.g. in "Error reading config".
+var levelPrefixRe = regexp.MustCompile(`(?i)^\s*(?:\[` + levelNamesRe + `\]:?|` + levelNamesRe + `:)(?:\s+|$)`)
+
+// levelNames maps lowercase level names found in output to levels.
+var levelNames = map[string]Level{
+	"trace":    LevelDebug,
+	"debug":    LevelDebug,
+	"info":     LevelInfo,
+	"notice":   LevelInfo,
+	"warn":     LevelWarn,
+	"warning":  LevelWarn,
+	"error":    LevelError,
+	"err":      LevelError,
+	"fatal":    LevelError,
+	"panic":    LevelError,
+	"critical": LevelError,
+	"crit":     LevelError,
+}
+
+// WriterOption configures a writer returned by [NewWriter].
+type WriterOption func(*lineWriter)
+
+// WithoutLevelDetection logs every line at the writer level, without
+// parsing level prefixes or JSON lines.
+func WithoutLevelDetection() WriterOption {
+	return func(w *lineWriter) {
+		w.detect = false
+	}
+}
+
+// WithMaxLineLength sets the number of buffered bytes after which a partial
+// line is emitted as a record, 64 KiB by default.
+func WithMaxLineLength(n int) WriterOption {
+	return func(w *lineWriter) {
+		w.maxLine = n
+	}
+}
+
+// lineWriter emits a record for each line written to it.
+type lineWriter struct {
+	l       Logger
+	level   Level
+	detect  bool
+	maxLine int
+
+	mu     sync.Mutex
+	buf    []byte
+	closed bool
+}
+
+// NewWriter returns an [io.WriteCloser] that logs each line written to it
+// through l, e.g. to capture output of a subprocess. Partial lines are
+// buffered until completed or the writer is closed. Lines are logged at
+// level unless they start with a level prefix such as "ERROR:" or "[warn]",
+// or are JSON objects with "level" and "msg" fields, whose other fields
+// become attributes.
+// Use [Logger.With] to add attributes like the stream name or process ID:
+//
+//	cmd.Stderr = logging.NewWriter(logger.With("stream", "stderr"), logging.LevelWarn)
+func NewWriter(l Logger, level Level, options ...WriterOption) io.WriteCloser {
+	w := &lineWriter{l: l, level: level, detect: true, maxLine: defaultMaxLineLength}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// Write implements [io.Writer].
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	for w.maxLine > 0 && len(w.buf) >= w.maxLine {
+		w.emit(w.buf[:w.maxLine])
+		w.buf = w.buf[w.maxLine:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil // release consumed memory
+	}
+	return len(p), nil
+}
+
+// Close logs a buffered partial line and rejects further writes,
+// implements [io.Closer].
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if len(w.buf) > 0 {
+		w.emit(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+// emit logs a single line, skipping blank lines.
+func (w *lineWriter) emit(line []byte) {
+	s := strings.TrimRight(string(line), "\r")
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+
+	if w.detect {
+		if msg, level, args, ok := parseJSONLine(s, w.level); ok {
+			LogAt(w.l, level, msg, args...)
+			return
+		}
+		if m := levelPrefixRe.FindStringSubmatch(s); m != nil {
+			LogAt(w.l, levelNames[strings.ToLower(m[1]+m[2])], s[len(m[0]):])
+			return
+		}
+	}
+	LogAt(w.l, w.level, s)
+}
+
+// parseJSONLine parses a JSON object line into a message, level, def if
+// missing, and key-value pairs of the remaining fields in key order.
+// Time fields are dropped, the record gets its own time.
+func parseJSONLine(s string, def Level) (msg string, level Level, args []any, ok bool) {
+	if !strings.HasPrefix(strings.TrimSpace(s), "{") {
+		return "", def, nil, false
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(s), &fields); err != nil {
+		return "", def, nil, false
+	}
+
+	level = def
+	for _, key := range []string{"level", "lvl", "severity"} {
+		if name, isString := fields[key].(string); isString {
+			if lvl, known := levelNames[strings.ToLower(name)]; known {
+				level = lvl
+				delete(fields, key)
+				break
+			}
+		}
+	}
+	for _, key := range []string{"msg", "message"} {
+		if m, isString := fields[key].(string); isString {
+			msg = m
+			delete(fields, key)
+			break
+		}
+	}
+	for _, key := range []string{"time", "ts", "timestamp"} {
+		delete(fields, key)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	args = make([]any, 0, 2*len(keys))
+	for _, key := range keys {
+		args = append(args, key, fields[key])
+	}
+	return msg, level, args, true
+}
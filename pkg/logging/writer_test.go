@@ -0,0 +1,129 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// writerLines decodes JSON records into generic maps.
+func writerLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for line := range strings.Lines(buf.String()) {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("failed to parse log line: %v: %s", err, line)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+// TestWriter verifies line splitting, partial line buffering and flushing on close.
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON).With("stream", "stderr", "pid", 42)
+	w := logging.NewWriter(l, logging.LevelWarn)
+
+	for _, chunk := range []string{"first li", "ne\r\nsecond line\n\n", "trailing"} {
+		if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("write returned %d, %v", n, err)
+		}
+	}
+	if got := len(writerLines(t, &buf)); got != 2 {
+		t.Fatalf("expected 2 records before close, got %d: %s", got, buf.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if _, err := w.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expected os.ErrClosed after close, got %v", err)
+	}
+
+	lines := writerLines(t, &buf)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records, got %d: %s", len(lines), buf.String())
+	}
+	for i, want := range []string{"first line", "second line", "trailing"} {
+		if lines[i]["msg"] != want || lines[i]["level"] != "WARN" ||
+			lines[i]["stream"] != "stderr" || lines[i]["pid"] != float64(42) {
+			t.Errorf("unexpected record %d: %v", i, lines[i])
+		}
+	}
+}
+
+// TestWriterLevelDetection verifies level prefixes and JSON lines.
+func TestWriterLevelDetection(t *testing.T) {
+	tt := []struct {
+		name      string
+		line      string
+		options   []logging.WriterOption
+		wantLevel string
+		wantMsg   string
+		wantAttr  string
+	}{
+		{"Plain", "starting up", nil, "INFO", "starting up", ""},
+		{"ErrorPrefix", "ERROR: disk full", nil, "ERROR", "disk full", ""},
+		{"BracketPrefix", "[warn] retrying", nil, "WARN", "retrying", ""},
+		{"BracketColonPrefix", "[ERROR]: disk full", nil, "ERROR", "disk full", ""},
+		{"SpaceNotPrefix", "DEBUG cache miss", nil, "INFO", "DEBUG cache miss", ""},
+		{"ErrorWord", "Error reading config", nil, "INFO", "Error reading config", ""},
+		{"PanicWord", "Panic recovered in worker", nil, "INFO", "Panic recovered in worker", ""},
+		{"ColonInWord", "errors: 3", nil, "INFO", "errors: 3", ""},
+		{"UnclosedBracket", "[warn retrying", nil, "INFO", "[warn retrying", ""},
+		{"FatalPrefix", "fatal: cannot open", nil, "ERROR", "cannot open", ""},
+		{"WordNotPrefix", "information only", nil, "INFO", "information only", ""},
+		{"JSON", `{"level":"warn","msg":"slow","ts":1,"took":3}`, nil, "WARN", "slow", "took"},
+		{"JSONNoLevel", `{"message":"hello","user":"bob"}`, nil, "INFO", "hello", "user"},
+		{"InvalidJSON", `{"msg":`, nil, "INFO", `{"msg":`, ""},
+		{"Disabled", "ERROR: disk full", []logging.WriterOption{logging.WithoutLevelDetection()}, "INFO", "ERROR: disk full", ""},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := logging.NewLogger(&buf, logging.JSON)
+			l.SetLevel(logging.LevelDebug)
+			w := logging.NewWriter(l, logging.LevelInfo, tc.options...)
+			_, _ = w.Write([]byte(tc.line + "\n"))
+
+			lines := writerLines(t, &buf)
+			if len(lines) != 1 {
+				t.Fatalf("expected 1 record, got %d: %s", len(lines), buf.String())
+			}
+			got := lines[0]
+			if got["level"] != tc.wantLevel || got["msg"] != tc.wantMsg {
+				t.Errorf("expected %s %q, got %v", tc.wantLevel, tc.wantMsg, got)
+			}
+			if _, ok := got[tc.wantAttr]; tc.wantAttr != "" && !ok {
+				t.Errorf("expected attribute %q, got %v", tc.wantAttr, got)
+			}
+			if _, ok := got["ts"]; ok {
+				t.Errorf("expected time field to be dropped, got %v", got)
+			}
+		})
+	}
+}
+
+// TestWriterMaxLineLength verifies that long partial lines are split.
+func TestWriterMaxLineLength(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+	w := logging.NewWriter(l, logging.LevelInfo, logging.WithMaxLineLength(4))
+
+	_, _ = w.Write([]byte("abcdefghij"))
+	_ = w.Close()
+
+	var msgs []string
+	for _, line := range writerLines(t, &buf) {
+		msgs = append(msgs, line["msg"].(string))
+	}
+	if strings.Join(msgs, ",") != "abcd,efgh,ij" {
+		t.Errorf("expected lines split at 4 bytes, got %q", msgs)
+	}
+}
@@ -0,0 +1,230 @@
+// Package logparse reconstructs [slog.Record] values from log output,
+// e.g. to re-render JSON logs of production services through
+// [conslog.ConsoleHandler]. Attribute order is preserved and nested
+// objects become groups.
+package logparse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys names the fields of a JSON log format holding the record time,
+// level and message. Other fields become attributes.
+type Keys struct {
+	Time    string
+	Level   string
+	Message string
+}
+
+// Key mappings of common JSON loggers.
+var (
+	SlogKeys    = Keys{Time: slog.TimeKey, Level: slog.LevelKey, Message: slog.MessageKey}
+	ZapKeys     = Keys{Time: "ts", Level: "level", Message: "msg"}
+	LogrusKeys  = Keys{Time: "time", Level: "level", Message: "msg"}
+	ZerologKeys = Keys{Time: "time", Level: "level", Message: "message"}
+)
+
+// FormatKeys returns the key mapping of a logger by name: slog, zap, logrus or zerolog.
+func FormatKeys(name string) (Keys, bool) {
+	switch strings.ToLower(name) {
+	case "slog", "":
+		return SlogKeys, true
+	case "zap":
+		return ZapKeys, true
+	case "logrus":
+		return LogrusKeys, true
+	case "zerolog":
+		return ZerologKeys, true
+	}
+	return Keys{}, false
+}
+
+// ErrNotObject is returned for lines that are not JSON objects.
+var ErrNotObject = errors.New("not a JSON object")
+
+// levelAliases maps level names of other loggers to slog levels.
+var levelAliases = map[string]slog.Level{
+	"trace":   slog.LevelDebug - 4,
+	"warning": slog.LevelWarn,
+	"dpanic":  slog.LevelError + 2,
+	"panic":   slog.LevelError + 4,
+	"fatal":   slog.LevelError + 4,
+	"crit":    slog.LevelError + 4,
+}
+
+// ParseLevel parses a level name of slog, zap, logrus or zerolog,
+// case-insensitive, e.g. "WARN", "warning", "INFO+2" or "fatal".
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err == nil {
+		return l, nil
+	}
+	if l, ok := levelAliases[strings.ToLower(s)]; ok {
+		return l, nil
+	}
+	return 0, fmt.Errorf("unknown level %q", s)
+}
+
+// ParseJSON parses a JSON object line into a record. Time values may be
+// RFC 3339 strings or Unix timestamps in seconds, milliseconds,
+// microseconds or nanoseconds. A missing level is Info, fields of unknown
+// levels or times are kept as attributes.
+func ParseJSON(line []byte, keys Keys) (slog.Record, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return slog.Record{}, ErrNotObject
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	v, err := decodeValue(dec, true)
+	if err != nil {
+		return slog.Record{}, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return slog.Record{}, errors.New("unexpected data after JSON object")
+	}
+
+	var (
+		t     time.Time
+		level = slog.LevelInfo
+		msg   string
+		attrs []slog.Attr
+	)
+	for _, a := range v.Group() {
+		switch {
+		case a.Key == keys.Time && t.IsZero():
+			if parsed, ok := parseTime(a.Value); ok {
+				t = parsed
+				continue
+			}
+		case a.Key == keys.Level:
+			if l, err := ParseLevel(a.Value.String()); a.Value.Kind() == slog.KindString && err == nil {
+				level = l
+				continue
+			}
+		case a.Key == keys.Message && a.Value.Kind() == slog.KindString:
+			msg = a.Value.String()
+			continue
+		}
+		attrs = append(attrs, a)
+	}
+
+	r := slog.NewRecord(t, level, msg, 0)
+	r.AddAttrs(attrs...)
+	return r, nil
+}
+
+// parseTime converts a JSON time value into a time.
+func parseTime(v slog.Value) (time.Time, bool) {
+	switch v.Kind() {
+	case slog.KindString:
+		t, err := time.Parse(time.RFC3339Nano, v.String())
+		return t, err == nil
+	case slog.KindInt64:
+		return unixTime(float64(v.Int64())), true
+	case slog.KindFloat64:
+		return unixTime(v.Float64()), true
+	}
+	return time.Time{}, false
+}
+
+// unixTime converts a Unix timestamp with a unit guessed by magnitude.
+func unixTime(f float64) time.Time {
+	abs := math.Abs(f)
+	switch {
+	case abs < 1e11:
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9))
+	case abs < 1e14:
+		return time.UnixMicro(int64(f * 1e3))
+	case abs < 1e17:
+		return time.UnixMicro(int64(f))
+	default:
+		return time.Unix(0, int64(f))
+	}
+}
+
+// decodeValue decodes the next JSON value, objects become groups keeping
+// key order when asGroup is set, otherwise maps as in nested arrays.
+func decodeValue(dec *json.Decoder, asGroup bool) (slog.Value, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return slog.Value{}, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '{':
+			if !asGroup {
+				return decodeMap(dec)
+			}
+			var attrs []slog.Attr
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return slog.Value{}, err
+				}
+				v, err := decodeValue(dec, true)
+				if err != nil {
+					return slog.Value{}, err
+				}
+				attrs = append(attrs, slog.Attr{Key: key.(string), Value: v})
+			}
+			_, err := dec.Token() // closing brace
+			return slog.GroupValue(attrs...), err
+		case '[':
+			var items []any
+			for dec.More() {
+				v, err := decodeValue(dec, false)
+				if err != nil {
+					return slog.Value{}, err
+				}
+				items = append(items, v.Any())
+			}
+			_, err := dec.Token() // closing bracket
+			if items == nil {
+				items = []any{}
+			}
+			return slog.AnyValue(items), err
+		}
+	case json.Number:
+		if i, err := strconv.ParseInt(tok.String(), 10, 64); err == nil {
+			return slog.Int64Value(i), nil
+		}
+		f, err := tok.Float64()
+		return slog.Float64Value(f), err
+	case string:
+		return slog.StringValue(tok), nil
+	case bool:
+		return slog.BoolValue(tok), nil
+	}
+	return slog.AnyValue(nil), nil
+}
+
+// decodeMap decodes the rest of an object inside an array into a map.
+func decodeMap(dec *json.Decoder) (slog.Value, error) {
+	m := make(map[string]any)
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return slog.Value{}, err
+		}
+		v, err := decodeValue(dec, false)
+		if err != nil {
+			return slog.Value{}, err
+		}
+		m[key.(string)] = v.Any()
+	}
+	_, err := dec.Token()
+	return slog.AnyValue(m), err
+}
@@ -0,0 +1,128 @@
+package logparse_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logparse"
+	"github.com/voler88/conslog/pkg/logtest"
+)
+
+// recordAttrs returns the attributes of r.
+func recordAttrs(r slog.Record) []slog.Attr {
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
+
+// TestParseJSON verifies key mappings, time formats and attribute order.
+func TestParseJSON(t *testing.T) {
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		name      string
+		line      string
+		format    string
+		wantTime  time.Time
+		wantLevel slog.Level
+		wantMsg   string
+		wantKeys  []string
+	}{
+		{
+			"Slog",
+			`{"time":"2024-01-02T03:04:05Z","level":"WARN+2","msg":"disk","z":1,"a":{"b":[1,{"c":2}]}}`,
+			"slog", want, slog.LevelWarn + 2, "disk", []string{"z", "a"},
+		},
+		{
+			"Zap",
+			`{"level":"dpanic","ts":1704164645,"caller":"main.go:12","msg":"bad"}`,
+			"zap", want, slog.LevelError + 2, "bad", []string{"caller"},
+		},
+		{
+			"Logrus",
+			`{"level":"warning","msg":"slow","time":"2024-01-02T03:04:05Z","took":"2s"}`,
+			"logrus", want, slog.LevelWarn, "slow", []string{"took"},
+		},
+		{
+			"Zerolog",
+			`{"level":"trace","time":1704164645000,"message":"tick"}`,
+			"zerolog", want, slog.LevelDebug - 4, "tick", nil,
+		},
+		{
+			"UnknownLevelAndTime",
+			`{"time":"yesterday","level":"loud","msg":"x"}`,
+			"slog", time.Time{}, slog.LevelInfo, "x", []string{"time", "level"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, ok := logparse.FormatKeys(tc.format)
+			if !ok {
+				t.Fatalf("unknown format %q", tc.format)
+			}
+			r, err := logparse.ParseJSON([]byte(tc.line), keys)
+			if err != nil {
+				t.Fatalf("ParseJSON failed: %v", err)
+			}
+			if !r.Time.Equal(tc.wantTime) || r.Level != tc.wantLevel || r.Message != tc.wantMsg {
+				t.Errorf("unexpected record: %v %v %q", r.Time, r.Level, r.Message)
+			}
+			var keys2 []string
+			for _, a := range recordAttrs(r) {
+				keys2 = append(keys2, a.Key)
+			}
+			if len(keys2) != len(tc.wantKeys) {
+				t.Fatalf("expected attributes %q, got %q", tc.wantKeys, keys2)
+			}
+			for i := range keys2 {
+				if keys2[i] != tc.wantKeys[i] {
+					t.Errorf("expected attributes %q, got %q", tc.wantKeys, keys2)
+				}
+			}
+		})
+	}
+}
+
+// TestParseJSONValues verifies conversion of nested values.
+func TestParseJSONValues(t *testing.T) {
+	r, err := logparse.ParseJSON([]byte(`{"msg":"m","req":{"status":200,"ratio":0.5,"ok":true,"tags":["a"],"none":null}}`), logparse.SlogKeys)
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v", err)
+	}
+	h := logtest.NewHandler(nil)
+	_ = h.Handle(t.Context(), r)
+	rec := h.Records()[0]
+	for path, want := range map[string]slog.Value{
+		"req.status": slog.Int64Value(200),
+		"req.ratio":  slog.Float64Value(0.5),
+		"req.ok":     slog.BoolValue(true),
+	} {
+		if got, ok := rec.Value(path); !ok || !got.Equal(want) {
+			t.Errorf("%s: expected %v, got %v", path, want, got)
+		}
+	}
+	if tags, _ := rec.Value("req.tags"); len(tags.Any().([]any)) != 1 {
+		t.Errorf("expected tags array, got %v", tags)
+	}
+}
+
+// TestParseJSONErrors verifies rejection of non-object lines.
+func TestParseJSONErrors(t *testing.T) {
+	for _, line := range []string{"plain text", "[1,2]", "", `{"msg":`, `{"a":1} {"b":2}`} {
+		_, err := logparse.ParseJSON([]byte(line), logparse.SlogKeys)
+		if err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+	if _, err := logparse.ParseJSON([]byte("text"), logparse.SlogKeys); !errors.Is(err, logparse.ErrNotObject) {
+		t.Errorf("expected ErrNotObject, got %v", err)
+	}
+	if _, ok := logparse.FormatKeys("log4j"); ok {
+		t.Error("expected unknown format")
+	}
+}
@@ -0,0 +1,213 @@
+package logparse
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotLogfmt is returned for lines without key=value pairs.
+var ErrNotLogfmt = errors.New("not a logfmt line")
+
+// ParseLogfmt parses a logfmt line, as written by [slog.TextHandler], into
+// a record. Dotted keys like "req.method" become nested groups, quoted keys
+// and values are unquoted. Unquoted values are converted to numbers,
+// booleans, durations and RFC 3339 times where possible, keys without a
+// value are true. A missing level is Info.
+func ParseLogfmt(line []byte, keys Keys) (slog.Record, error) {
+	pairs, err := splitLogfmt(strings.TrimSpace(string(line)))
+	if err != nil {
+		return slog.Record{}, err
+	}
+
+	var (
+		t     time.Time
+		level = slog.LevelInfo
+		msg   string
+		root  group
+	)
+	for _, p := range pairs {
+		switch {
+		case p.key == keys.Time && t.IsZero() && !p.bare:
+			if parsed, err := time.Parse(time.RFC3339Nano, p.value); err == nil {
+				t = parsed
+				continue
+			}
+		case p.key == keys.Level && !p.bare:
+			if l, err := ParseLevel(p.value); err == nil {
+				level = l
+				continue
+			}
+		case p.key == keys.Message && !p.bare:
+			msg = p.value
+			continue
+		}
+		root.add(strings.Split(p.key, "."), logfmtValue(p))
+	}
+
+	r := slog.NewRecord(t, level, msg, 0)
+	r.AddAttrs(root.attrs()...)
+	return r, nil
+}
+
+// pair is a key=value pair of a logfmt line.
+type pair struct {
+	key    string
+	value  string
+	quoted bool // value was quoted, it is never converted
+	bare   bool // key without value
+}
+
+// splitLogfmt splits a line into pairs, at least one must have a value.
+func splitLogfmt(s string) ([]pair, error) {
+	var (
+		pairs     []pair
+		hasValues bool
+	)
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+
+		key, n, _, err := scanLogfmtToken(s[i:], true)
+		if err != nil {
+			return nil, err
+		}
+		i += n
+		if key == "" || (i < len(s) && !strings.ContainsRune("= \t", rune(s[i]))) {
+			return nil, ErrNotLogfmt
+		}
+		if i >= len(s) || s[i] != '=' {
+			pairs = append(pairs, pair{key: key, bare: true})
+			continue
+		}
+		i++ // skip '='
+
+		value, n, quoted, err := scanLogfmtToken(s[i:], false)
+		if err != nil {
+			return nil, err
+		}
+		i += n
+		if i < len(s) && s[i] != ' ' && s[i] != '\t' {
+			return nil, ErrNotLogfmt
+		}
+		pairs = append(pairs, pair{key: key, value: value, quoted: quoted})
+		hasValues = true
+	}
+	if !hasValues {
+		return nil, ErrNotLogfmt
+	}
+	return pairs, nil
+}
+
+// scanLogfmtToken reads a quoted or unquoted key or value at the start of s,
+// returning it with the number of bytes consumed. Keys end at '='.
+func scanLogfmtToken(s string, isKey bool) (string, int, bool, error) {
+	if strings.HasPrefix(s, `"`) {
+		end := 1
+		for end < len(s) && s[end] != '"' {
+			if s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(s) {
+			return "", 0, false, fmt.Errorf("%w: unterminated quote", ErrNotLogfmt)
+		}
+		v, err := strconv.Unquote(s[:end+1])
+		if err != nil {
+			return "", 0, false, fmt.Errorf("%w: %v", ErrNotLogfmt, err)
+		}
+		return v, end + 1, true, nil
+	}
+
+	end := strings.IndexAny(s, " \t")
+	if end < 0 {
+		end = len(s)
+	}
+	if isKey {
+		if eq := strings.IndexByte(s[:end], '='); eq >= 0 {
+			end = eq
+		}
+		if strings.ContainsRune(s[:end], '"') {
+			return "", 0, false, ErrNotLogfmt
+		}
+	}
+	return s[:end], end, false, nil
+}
+
+// logfmtValue converts an unquoted value to the most specific type.
+func logfmtValue(p pair) slog.Value {
+	switch {
+	case p.bare:
+		return slog.BoolValue(true)
+	case p.quoted:
+		return slog.StringValue(p.value)
+	}
+	if i, err := strconv.ParseInt(p.value, 10, 64); err == nil {
+		return slog.Int64Value(i)
+	}
+	if f, err := strconv.ParseFloat(p.value, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return slog.Float64Value(f)
+	}
+	if p.value == "true" || p.value == "false" {
+		return slog.BoolValue(p.value == "true")
+	}
+	if d, err := time.ParseDuration(p.value); err == nil {
+		return slog.DurationValue(d)
+	}
+	if t, err := time.Parse(time.RFC3339Nano, p.value); err == nil {
+		return slog.TimeValue(t)
+	}
+	return slog.StringValue(p.value)
+}
+
+// group collects attributes of dotted keys into nested groups, keeping
+// the order in which keys first appear.
+type group struct {
+	entries []*entry
+}
+
+// entry is a value or a nested group.
+type entry struct {
+	key   string
+	value slog.Value
+	group *group // nil for values
+}
+
+// add adds a value at a dotted path.
+func (g *group) add(path []string, v slog.Value) {
+	if len(path) == 1 {
+		g.entries = append(g.entries, &entry{key: path[0], value: v})
+		return
+	}
+	var sub *group
+	for _, e := range g.entries {
+		if e.key == path[0] && e.group != nil {
+			sub = e.group
+		}
+	}
+	if sub == nil {
+		sub = new(group)
+		g.entries = append(g.entries, &entry{key: path[0], group: sub})
+	}
+	sub.add(path[1:], v)
+}
+
+// attrs converts the group into attributes.
+func (g *group) attrs() []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(g.entries))
+	for _, e := range g.entries {
+		if e.group != nil {
+			attrs = append(attrs, slog.Attr{Key: e.key, Value: slog.GroupValue(e.group.attrs()...)})
+		} else {
+			attrs = append(attrs, slog.Attr{Key: e.key, Value: e.value})
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,82 @@
+package logparse_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logparse"
+	"github.com/voler88/conslog/pkg/logtest"
+)
+
+// TestParseLogfmtRoundTrip verifies parsing output of slog.TextHandler.
+func TestParseLogfmtRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	l.With("app", "api").WithGroup("req").Warn("slow \"request\"",
+		"method", "GET",
+		slog.Group("headers", "accept", "application/json", "x id", "a=b"),
+		"status", 200,
+		"took", 1500*time.Millisecond,
+		"ratio", 0.5,
+		"cached", false,
+		"at", at,
+		"code", "007 ",
+	)
+
+	r, err := logparse.ParseLogfmt(buf.Bytes(), logparse.SlogKeys)
+	if err != nil {
+		t.Fatalf("ParseLogfmt failed: %v: %s", err, buf.String())
+	}
+	if r.Level != slog.LevelWarn || r.Message != `slow "request"` || r.Time.IsZero() {
+		t.Errorf("unexpected record header: %v %v %q", r.Time, r.Level, r.Message)
+	}
+
+	h := logtest.NewHandler(nil)
+	_ = h.Handle(t.Context(), r)
+	rec := h.Records()[0]
+	if len(rec.Attrs) != 2 || rec.Attrs[0].Key != "app" || rec.Attrs[1].Key != "req" {
+		t.Fatalf("expected app and req attributes, got %v", rec.Attrs)
+	}
+	for path, want := range map[string]slog.Value{
+		"app":                slog.StringValue("api"),
+		"req.method":         slog.StringValue("GET"),
+		"req.headers.accept": slog.StringValue("application/json"),
+		"req.headers.x id":   slog.StringValue("a=b"),
+		"req.status":         slog.Int64Value(200),
+		"req.took":           slog.DurationValue(1500 * time.Millisecond),
+		"req.ratio":          slog.Float64Value(0.5),
+		"req.cached":         slog.BoolValue(false),
+		"req.at":             slog.TimeValue(at),
+		"req.code":           slog.StringValue("007 "),
+	} {
+		got, ok := rec.Value(path)
+		if !ok || !got.Equal(want) {
+			t.Errorf("%s: expected %v, got %v (found %t)", path, want, got, ok)
+		}
+	}
+}
+
+// TestParseLogfmt verifies key mappings, bare keys and errors.
+func TestParseLogfmt(t *testing.T) {
+	r, err := logparse.ParseLogfmt([]byte(`ts=2024-01-02T03:04:05Z level=warning message=hi debug nan=NaN`), logparse.Keys{
+		Time: "ts", Level: "level", Message: "message",
+	})
+	if err != nil {
+		t.Fatalf("ParseLogfmt failed: %v", err)
+	}
+	h := logtest.NewHandler(nil)
+	_ = h.Handle(t.Context(), r)
+	if !h.Has(logtest.Level(slog.LevelWarn), logtest.Message("hi"), logtest.Attr("debug", true), logtest.Attr("nan", "NaN")) {
+		t.Errorf("unexpected record: %+v", h.Records())
+	}
+
+	for _, line := range []string{"", "plain words only", `msg="unterminated`, `"a"b=1`, `=1`} {
+		if _, err := logparse.ParseLogfmt([]byte(line), logparse.SlogKeys); !errors.Is(err, logparse.ErrNotLogfmt) {
+			t.Errorf("%q: expected ErrNotLogfmt, got %v", line, err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package logquery
+
+import (
+	"cmp"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Reserved field names, other fields refer to attributes.
+const (
+	fieldLevel   = "level"
+	fieldMsg     = "msg"
+	fieldMessage = "message"
+)
+
+// Fields gives expressions access to a log entry, e.g. a record together
+// with attributes bound to its handler or a parsed JSON line.
+type Fields interface {
+	Level() slog.Level
+	Message() string
+	Attr(path string) (slog.Value, bool) // dot separated path through groups
+}
+
+// Match reports whether f matches the expression.
+func (e *Expr) Match(f Fields) bool {
+	return e.root.eval(f)
+}
+
+// MatchRecord reports whether the attributes of r match the expression.
+func (e *Expr) MatchRecord(r slog.Record) bool {
+	return e.Match(RecordFields(r))
+}
+
+// RecordFields returns [Fields] of a record, group attributes are
+// accessed by dot separated paths like "http.status".
+func RecordFields(r slog.Record) Fields {
+	return &recordFields{r: r}
+}
+
+// recordFields implements [Fields] for a record, attributes are
+// flattened on first access.
+type recordFields struct {
+	r     slog.Record
+	attrs map[string]slog.Value
+}
+
+// Level implements [Fields] interface.
+func (f *recordFields) Level() slog.Level { return f.r.Level }
+
+// Message implements [Fields] interface.
+func (f *recordFields) Message() string { return f.r.Message }
+
+// Attr implements [Fields] interface.
+func (f *recordFields) Attr(path string) (slog.Value, bool) {
+	if f.attrs == nil {
+		f.attrs = make(map[string]slog.Value, f.r.NumAttrs())
+		f.r.Attrs(func(a slog.Attr) bool {
+			flatten(f.attrs, "", a)
+			return true
+		})
+	}
+	v, ok := f.attrs[path]
+	return v, ok
+}
+
+// flatten adds a resolved attribute to m, groups by dot separated paths.
+func flatten(m map[string]slog.Value, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		m[prefix+a.Key] = v
+		return
+	}
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, ga := range v.Group() {
+		flatten(m, prefix, ga)
+	}
+}
+
+// node is an element of the expression tree.
+type node interface {
+	eval(f Fields) bool
+}
+
+// andNode matches if both operands match.
+type andNode struct{ left, right node }
+
+func (n andNode) eval(f Fields) bool { return n.left.eval(f) && n.right.eval(f) }
+
+// orNode matches if either operand matches.
+type orNode struct{ left, right node }
+
+func (n orNode) eval(f Fields) bool { return n.left.eval(f) || n.right.eval(f) }
+
+// notNode negates its operand.
+type notNode struct{ n node }
+
+func (n notNode) eval(f Fields) bool { return !n.n.eval(f) }
+
+// existsNode matches if a field is present and not false.
+type existsNode struct{ field string }
+
+func (n existsNode) eval(f Fields) bool {
+	switch n.field {
+	case fieldLevel:
+		return true
+	case fieldMsg, fieldMessage:
+		return f.Message() != ""
+	}
+	v, ok := f.Attr(n.field)
+	return ok && !(v.Kind() == slog.KindBool && !v.Bool())
+}
+
+// compareNode compares a field with a value. Attributes are compared as
+// numbers, booleans, durations or times if both sides can be, otherwise
+// as strings. Comparisons with missing attributes do not match.
+type compareNode struct {
+	field  string
+	op     string
+	text   string         // value as written
+	re     *regexp.Regexp // value of ~ and !~
+	level  slog.Level     // value compared with the level field
+	num    float64
+	isNum  bool
+	b      bool
+	isBool bool
+}
+
+func (n compareNode) eval(f Fields) bool {
+	switch n.field {
+	case fieldLevel:
+		if n.re != nil {
+			return n.match(f.Level().String())
+		}
+		return n.result(cmp.Compare(f.Level(), n.level))
+	case fieldMsg, fieldMessage:
+		return n.compareString(f.Message())
+	}
+
+	v, ok := f.Attr(n.field)
+	if !ok {
+		return false
+	}
+	if n.re != nil {
+		return n.match(v.String())
+	}
+
+	switch v.Kind() {
+	case slog.KindInt64:
+		if n.isNum {
+			return n.result(cmp.Compare(float64(v.Int64()), n.num))
+		}
+	case slog.KindUint64:
+		if n.isNum {
+			return n.result(cmp.Compare(float64(v.Uint64()), n.num))
+		}
+	case slog.KindFloat64:
+		if n.isNum {
+			return n.result(cmp.Compare(v.Float64(), n.num))
+		}
+	case slog.KindBool:
+		if n.isBool && (n.op == "==" || n.op == "!=") {
+			return (v.Bool() == n.b) == (n.op == "==")
+		}
+	case slog.KindDuration:
+		if d, err := time.ParseDuration(n.text); err == nil {
+			return n.result(cmp.Compare(v.Duration(), d))
+		}
+	case slog.KindTime:
+		if t, err := time.Parse(time.RFC3339Nano, n.text); err == nil {
+			return n.result(v.Time().Compare(t))
+		}
+	case slog.KindString:
+		if x, err := strconv.ParseFloat(v.String(), 64); err == nil && n.isNum {
+			return n.result(cmp.Compare(x, n.num))
+		}
+	}
+	return n.compareString(v.String())
+}
+
+// compareString compares s with the value as strings.
+func (n compareNode) compareString(s string) bool {
+	if n.re != nil {
+		return n.match(s)
+	}
+	return n.result(cmp.Compare(s, n.text))
+}
+
+// match applies the regular expression of ~ and !~ to s.
+func (n compareNode) match(s string) bool {
+	return n.re.MatchString(s) == (n.op == "~")
+}
+
+// result converts the result of a three-way comparison for the operator.
+func (n compareNode) result(c int) bool {
+	switch n.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package logquery_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logquery"
+)
+
+// TestMatchRecord verifies evaluation of expressions over a record.
+func TestMatchRecord(t *testing.T) {
+	r := slog.NewRecord(time.Now(), slog.LevelError, "upstream timeout", 0)
+	r.AddAttrs(
+		slog.Group("http", slog.Int("status", 504), slog.String("method", "GET")),
+		slog.Bool("retry", false),
+		slog.Bool("cached", true),
+		slog.Duration("took", 1500*time.Millisecond),
+		slog.Time("at", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		slog.String("code", "42"),
+		slog.Float64("ratio", 0.25),
+	)
+
+	tt := []struct {
+		expr string
+		want bool
+	}{
+		{`level>=warn && http.status>=500 && msg~"timeout"`, true},
+		{`level<error`, false},
+		{`level==4`, false},
+		{`level~"^ERR"`, true},
+		{`msg=="upstream timeout"`, true},
+		{`message!~timeout`, false},
+		{`http.method==GET && http.status<500`, false},
+		{`http.method=="GET" || missing==1`, true},
+		{`missing!=1`, false},
+		{`!missing && cached && !retry`, true},
+		{`retry==false && cached!=false`, true},
+		{`took>1s && took<=1500ms`, true},
+		{`at>=2024-01-01T00:00:00Z && at<2024-01-02T03:04:05Z`, false},
+		{`code>=40 && code<50`, true},
+		{`ratio==0.25`, true},
+		{`http.method>F`, true},
+		{`(level==info || level==error) && !(http.status==200)`, true},
+	}
+	for _, tc := range tt {
+		if got := logquery.MustParse(tc.expr).MatchRecord(r); got != tc.want {
+			t.Errorf("%s: expected %t, got %t", tc.expr, tc.want, got)
+		}
+	}
+}
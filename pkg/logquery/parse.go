@@ -0,0 +1,298 @@
+// Package logquery implements a small expression language for filtering
+// log records, e.g.
+//
+//	level>=warn && http.status>=500 && msg~"timeout"
+//
+// Expressions compare fields with values and combine comparisons with
+// && (and), || (or), ! (not) and parentheses. Fields are "level", "msg"
+// and dot separated attribute paths like "http.status". Operators are
+// == != < <= > >= and ~ !~ for regular expression matches. Values are
+// quoted strings, numbers, true, false or bare words such as level names.
+// A field without an operator matches if the attribute exists and is not false.
+package logquery
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Expr is a parsed expression, safe for concurrent use.
+type Expr struct {
+	src  string
+	root node
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Parse parses an expression.
+func Parse(s string) (*Expr, error) {
+	p := &parser{lex: lexer{src: s}}
+	p.next()
+	root, err := p.parseOr()
+	if err == nil {
+		err = p.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return &Expr{src: s, root: root}, nil
+}
+
+// MustParse is like [Parse] but panics on invalid expressions.
+func MustParse(s string) *Expr {
+	e, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// tokenKind is the type of a lexical token.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp     // comparison operator
+	tokAnd    // &&
+	tokOr     // ||
+	tokNot    // !
+	tokLParen // (
+	tokRParen // )
+)
+
+// token is a lexical token with its position in the source.
+type token struct {
+	kind tokenKind
+	text string // unquoted for strings
+	pos  int
+}
+
+// String describes the token for error messages.
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// lexer splits an expression into tokens.
+type lexer struct {
+	src string
+	pos int
+}
+
+// isIdentRune reports whether r may appear in a field name or bare word.
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-+/:", r)
+}
+
+// next returns the next token.
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && (l.src[l.pos] == ' ' || l.src[l.pos] == '\t' || l.src[l.pos] == '\n') {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	rest := l.src[l.pos:]
+	for _, op := range []struct {
+		text string
+		kind tokenKind
+	}{
+		{"&&", tokAnd}, {"||", tokOr}, {"==", tokOp}, {"!=", tokOp}, {"!~", tokOp},
+		{">=", tokOp}, {"<=", tokOp}, {">", tokOp}, {"<", tokOp}, {"~", tokOp},
+		{"=", tokOp}, {"!", tokNot}, {"(", tokLParen}, {")", tokRParen},
+	} {
+		if strings.HasPrefix(rest, op.text) {
+			l.pos += len(op.text)
+			text := op.text
+			if text == "=" {
+				text = "=="
+			}
+			return token{kind: op.kind, text: text, pos: start}, nil
+		}
+	}
+
+	switch c := rest[0]; {
+	case c == '"' || c == '\'':
+		return l.lexString(c)
+	case c >= '0' && c <= '9' || (c == '-' && len(rest) > 1 && rest[1] >= '0' && rest[1] <= '9'):
+		tok := l.lexWord(start)
+		if _, err := strconv.ParseFloat(tok.text, 64); err == nil {
+			tok.kind = tokNumber
+		}
+		return tok, nil
+	}
+
+	r, _ := utf8.DecodeRuneInString(rest)
+	if !isIdentRune(r) {
+		return token{}, fmt.Errorf("position %d: unexpected character %q", start+1, r)
+	}
+	return l.lexWord(start), nil
+}
+
+// lexWord reads an identifier, bare word or number.
+func (l *lexer) lexWord(start int) token {
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !isIdentRune(r) {
+			break
+		}
+		l.pos += size
+	}
+	return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}
+}
+
+// lexString reads a string quoted with q, backslash escapes a quote or backslash.
+func (l *lexer) lexString(q byte) (token, error) {
+	start := l.pos
+	var b strings.Builder
+	for l.pos++; l.pos < len(l.src); l.pos++ {
+		switch c := l.src[l.pos]; c {
+		case q:
+			l.pos++
+			return token{kind: tokString, text: b.String(), pos: start}, nil
+		case '\\':
+			if l.pos+1 < len(l.src) {
+				l.pos++
+				c = l.src[l.pos]
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return token{}, fmt.Errorf("position %d: unterminated string", start+1)
+}
+
+// parser builds an expression tree by recursive descent.
+type parser struct {
+	lex lexer
+	tok token
+	err error // lexical error, reported when the token is used
+}
+
+// next advances to the next token.
+func (p *parser) next() {
+	tok, err := p.lex.next()
+	if err != nil && p.err == nil {
+		p.err = err
+		tok = token{kind: tokEOF, pos: p.lex.pos}
+	}
+	p.tok = tok
+}
+
+// errorf returns an error at the current token, or the pending lexical error.
+func (p *parser) errorf(format string, args ...any) error {
+	if p.err != nil {
+		return p.err
+	}
+	return fmt.Errorf("position %d: %s", p.tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+// parseOr parses: and ('||' and)*.
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.tok.kind == tokOr {
+		p.next()
+		var right node
+		if right, err = p.parseAnd(); err == nil {
+			left = orNode{left, right}
+		}
+	}
+	return left, err
+}
+
+// parseAnd parses: unary ('&&' unary)*.
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	for err == nil && p.tok.kind == tokAnd {
+		p.next()
+		var right node
+		if right, err = p.parseUnary(); err == nil {
+			left = andNode{left, right}
+		}
+	}
+	return left, err
+}
+
+// parseUnary parses: '!' unary | '(' or ')' | comparison.
+func (p *parser) parseUnary() (node, error) {
+	switch p.tok.kind {
+	case tokNot:
+		p.next()
+		n, err := p.parseUnary()
+		return notNode{n}, err
+	case tokLParen:
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected \")\", got %s", p.tok)
+		}
+		p.next()
+		return n, nil
+	case tokIdent:
+		return p.parseComparison()
+	}
+	return nil, p.errorf("expected field, got %s", p.tok)
+}
+
+// parseComparison parses: field [op value].
+func (p *parser) parseComparison() (node, error) {
+	field := p.tok.text
+	p.next()
+	if p.tok.kind != tokOp {
+		return existsNode{field}, p.err
+	}
+	op := p.tok.text
+	p.next()
+
+	v := p.tok
+	switch v.kind {
+	case tokIdent, tokString, tokNumber:
+	default:
+		return nil, p.errorf("expected value after %q, got %s", op, p.tok)
+	}
+	p.next()
+
+	cmp := compareNode{field: field, op: op, text: v.text}
+	switch {
+	case op == "~" || op == "!~":
+		re, err := regexp.Compile(v.text)
+		if err != nil {
+			return nil, fmt.Errorf("position %d: invalid regular expression: %w", v.pos+1, err)
+		}
+		cmp.re = re
+	case field == fieldLevel:
+		if n, err := strconv.Atoi(v.text); err == nil {
+			cmp.level = slog.Level(n)
+		} else if err := cmp.level.UnmarshalText([]byte(v.text)); err != nil {
+			return nil, fmt.Errorf("position %d: invalid level %q", v.pos+1, v.text)
+		}
+	case v.kind == tokNumber:
+		cmp.num, _ = strconv.ParseFloat(v.text, 64)
+		cmp.isNum = true
+	case v.kind == tokIdent && (v.text == "true" || v.text == "false"):
+		cmp.isBool, cmp.b = true, v.text == "true"
+	}
+	return cmp, p.err
+}
@@ -0,0 +1,58 @@
+package logquery_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logquery"
+)
+
+// TestParse verifies accepted syntax and error positions.
+func TestParse(t *testing.T) {
+	valid := []string{
+		`level>=warn && http.status>=500 && msg~"timeout"`,
+		`!(user.admin) || level == ERROR+2`,
+		`env=prod && took > 1.5s && ratio<=0.25 && path != '/health\'s'`,
+		`  retry  `,
+	}
+	for _, s := range valid {
+		e, err := logquery.Parse(s)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", s, err)
+			continue
+		}
+		if e.String() != s {
+			t.Errorf("expected source %q, got %q", s, e.String())
+		}
+	}
+
+	invalid := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"", "position 1: expected field, got end of expression"},
+		{"level>=", "position 8: expected value"},
+		{"level>=loud", `position 8: invalid level "loud"`},
+		{"(a || b", `position 8: expected ")"`},
+		{"a b", `position 3: unexpected "b"`},
+		{`msg~"("`, "position 5: invalid regular expression"},
+		{`msg=="open`, "position 6: unterminated string"},
+		{"a && #", "position 6: unexpected character '#'"},
+	}
+	for _, tc := range invalid {
+		_, err := logquery.Parse(tc.expr)
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("Parse(%q): expected error containing %q, got %v", tc.expr, tc.wantErr, err)
+		}
+	}
+}
+
+// TestMustParse verifies the panic on invalid expressions.
+func TestMustParse(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	logquery.MustParse("&&")
+}
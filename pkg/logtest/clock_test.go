@@ -0,0 +1,32 @@
+package logtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog"
+	"github.com/voler88/conslog/pkg/logtest"
+)
+
+// TestClock verifies advancing, setting and stepping the fake clock.
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var c conslog.Clock = logtest.NewClock(start)
+	clock := c.(*logtest.Clock)
+
+	if got := clock.Now(); !got.Equal(start) {
+		t.Errorf("expected start time, got %v", got)
+	}
+	clock.Advance(time.Minute)
+	if got := clock.Now(); !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("expected advanced time, got %v", got)
+	}
+
+	clock.Set(start)
+	clock.SetStep(time.Second)
+	for i := range 3 {
+		if got := clock.Now(); !got.Equal(start.Add(time.Duration(i) * time.Second)) {
+			t.Errorf("reading %d: expected stepped time, got %v", i, got)
+		}
+	}
+}
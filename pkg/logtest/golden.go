@@ -0,0 +1,101 @@
+package logtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable that makes [Golden] and
+// [GoldenANSI] rewrite golden files with the current output instead of
+// comparing, e.g. CONSLOG_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "CONSLOG_UPDATE_GOLDEN"
+
+// update reports whether golden files are rewritten.
+func update() bool {
+	ok, _ := strconv.ParseBool(os.Getenv(UpdateGoldenEnv))
+	return ok
+}
+
+// escapeReplacer makes ANSI escape sequences visible.
+var escapeReplacer = strings.NewReplacer("\x1b", "␛")
+
+// VisualizeANSI replaces escape characters of ANSI sequences with a visible
+// "␛", e.g. "␛[90mkey␛[0m", so that colors can be reviewed in diffs.
+func VisualizeANSI(s string) string {
+	return escapeReplacer.Replace(s)
+}
+
+// Golden compares got with testdata/<name>.golden and reports mismatching
+// lines. With [UpdateGoldenEnv] set, got is written to the golden file instead.
+func Golden(tb testing.TB, name string, got []byte) {
+	tb.Helper()
+	golden(tb, filepath.Join("testdata", name+".golden"), string(got))
+}
+
+// GoldenANSI is like [Golden] for output with ANSI escape sequences. The
+// golden file holds the output visualized by [VisualizeANSI], updates also
+// write raw output to testdata/<name>.ansi for viewing in a terminal.
+func GoldenANSI(tb testing.TB, name string, got []byte) {
+	tb.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if update() {
+		writeGolden(tb, filepath.Join("testdata", name+".ansi"), string(got))
+	}
+	golden(tb, path, VisualizeANSI(string(got)))
+}
+
+// golden compares got with the file at path or updates it.
+func golden(tb testing.TB, path, got string) {
+	tb.Helper()
+	if update() {
+		writeGolden(tb, path, got)
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("failed to read golden file, set %s=1 to create it: %v", UpdateGoldenEnv, err)
+	}
+	if diff := lineDiff(string(want), got); diff != "" {
+		tb.Errorf("output differs from %s, set %s=1 to accept:\n%s", path, UpdateGoldenEnv, diff)
+	}
+}
+
+// writeGolden writes content to path, creating parent directories.
+func writeGolden(tb testing.TB, path, content string) {
+	tb.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		tb.Fatalf("failed to create golden directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		tb.Fatalf("failed to write golden file: %v", err)
+	}
+}
+
+// lineDiff lists lines that differ between want and got, empty if equal.
+func lineDiff(want, got string) string {
+	if want == got {
+		return ""
+	}
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	var b strings.Builder
+	for i := range max(len(wantLines), len(gotLines)) {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			fmt.Fprintf(&b, "line %d:\n  - %q\n  + %q\n", i+1, w, g)
+		}
+	}
+	return b.String()
+}
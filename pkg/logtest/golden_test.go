@@ -0,0 +1,46 @@
+package logtest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logtest"
+)
+
+// TestVisualizeANSI verifies that escape characters become visible.
+func TestVisualizeANSI(t *testing.T) {
+	got := logtest.VisualizeANSI("\x1b[90mkey\x1b[0m: value")
+	if got != "␛[90mkey␛[0m: value" {
+		t.Errorf("unexpected visualization: %q", got)
+	}
+}
+
+// TestGoldenANSI verifies comparison with testdata/sample.golden.
+func TestGoldenANSI(t *testing.T) {
+	t.Setenv(logtest.UpdateGoldenEnv, "")
+	logtest.GoldenANSI(t, "sample", []byte("first line\n\x1b[90mkey\x1b[0m: value\n"))
+
+	tb := &fakeTB{TB: t}
+	logtest.GoldenANSI(tb, "sample", []byte("first line\n\x1b[90mkey\x1b[0m: other\n"))
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "line 2:") ||
+		!strings.Contains(tb.errors[0], `+ "␛[90mkey␛[0m: other"`) {
+		t.Errorf("expected mismatch of line 2, got %q", tb.errors)
+	}
+}
+
+// TestGoldenUpdate verifies that golden files are written when the update
+// environment variable is set.
+func TestGoldenUpdate(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv(logtest.UpdateGoldenEnv, "1")
+	logtest.GoldenANSI(t, "new", []byte("\x1b[90mkey\x1b[0m\n"))
+
+	for name, want := range map[string]string{"new.golden": "␛[90mkey␛[0m\n", "new.ansi": "\x1b[90mkey\x1b[0m\n"} {
+		got, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil || string(got) != want {
+			t.Errorf("expected %s to hold %q, got %q: %v", name, want, got, err)
+		}
+	}
+}
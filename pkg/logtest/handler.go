@@ -0,0 +1,268 @@
+// Package logtest provides helpers for asserting on logs in tests.
+//
+// [Handler] is a [slog.Handler] and [Logger] a [logging.Logger] that record
+// every record with resolved attributes, queried with [Matcher] values:
+//
+//	l := logtest.NewLogger()
+//	run(l)
+//	if !l.Has(logtest.Level(slog.LevelError), logtest.Attr("user.id", 42)) {
+//		t.Error("expected error for user 42")
+//	}
+//
+// [NewWriter] routes handler output through [testing.TB.Log], so logs of
+// code under test appear next to the test that produced them.
+package logtest
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is a captured log record. Attributes are resolved, attributes
+// added with [slog.Logger.With] come first and groups opened with
+// [slog.Logger.WithGroup] are nested as group attributes.
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   []slog.Attr
+	PC      uintptr
+}
+
+// Value returns the value of the attribute at a dot separated path,
+// e.g. "req.method" for the "method" attribute in the "req" group.
+func (r Record) Value(path string) (slog.Value, bool) {
+	attrs := r.Attrs
+	keys := strings.Split(path, ".")
+	for i, key := range keys {
+		j := slices.IndexFunc(attrs, func(a slog.Attr) bool { return a.Key == key })
+		if j < 0 {
+			return slog.Value{}, false
+		}
+		if i == len(keys)-1 {
+			return attrs[j].Value, true
+		}
+		if attrs[j].Value.Kind() != slog.KindGroup {
+			return slog.Value{}, false
+		}
+		attrs = attrs[j].Value.Group()
+	}
+	return slog.Value{}, false
+}
+
+// Map returns attributes as a map with groups as nested maps.
+func (r Record) Map() map[string]any {
+	return attrsToMap(r.Attrs)
+}
+
+// attrsToMap converts resolved attributes into a map.
+func attrsToMap(attrs []slog.Attr) map[string]any {
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		if a.Value.Kind() == slog.KindGroup {
+			m[a.Key] = attrsToMap(a.Value.Group())
+		} else {
+			m[a.Key] = a.Value.Any()
+		}
+	}
+	return m
+}
+
+// Matcher reports whether a record matches a condition.
+type Matcher func(Record) bool
+
+// Level matches records at exactly level.
+func Level(level slog.Level) Matcher {
+	return func(r Record) bool { return r.Level == level }
+}
+
+// MinLevel matches records at or above level.
+func MinLevel(level slog.Level) Matcher {
+	return func(r Record) bool { return r.Level >= level }
+}
+
+// Message matches records with exactly msg.
+func Message(msg string) Matcher {
+	return func(r Record) bool { return r.Message == msg }
+}
+
+// MessageContains matches records whose message contains s.
+func MessageContains(s string) Matcher {
+	return func(r Record) bool { return strings.Contains(r.Message, s) }
+}
+
+// HasAttr matches records with an attribute at path, see [Record.Value].
+func HasAttr(path string) Matcher {
+	return func(r Record) bool {
+		_, ok := r.Value(path)
+		return ok
+	}
+}
+
+// Attr matches records with an attribute at path equal to value, compared
+// as [slog.Value] so that e.g. int and int64 values are equal.
+func Attr(path string, value any) Matcher {
+	want := slog.AnyValue(value).Resolve()
+	return func(r Record) bool {
+		v, ok := r.Value(path)
+		return ok && v.Equal(want)
+	}
+}
+
+// Recorder holds captured records, shared by a handler and its derived handlers.
+type Recorder struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+// add appends a record.
+func (rec *Recorder) add(r Record) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.records = append(rec.records, r)
+}
+
+// Records returns a copy of all captured records in logging order.
+func (rec *Recorder) Records() []Record {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return slices.Clone(rec.records)
+}
+
+// Find returns records matching all matchers.
+func (rec *Recorder) Find(matchers ...Matcher) []Record {
+	var found []Record
+	for _, r := range rec.Records() {
+		if matchAll(r, matchers) {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// Count returns the number of records matching all matchers.
+func (rec *Recorder) Count(matchers ...Matcher) int {
+	return len(rec.Find(matchers...))
+}
+
+// Has reports whether any record matches all matchers.
+func (rec *Recorder) Has(matchers ...Matcher) bool {
+	return slices.ContainsFunc(rec.Records(), func(r Record) bool {
+		return matchAll(r, matchers)
+	})
+}
+
+// Reset discards all captured records.
+func (rec *Recorder) Reset() {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.records = nil
+}
+
+// matchAll reports whether r matches all matchers.
+func matchAll(r Record, matchers []Matcher) bool {
+	for _, m := range matchers {
+		if !m(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// groupOrAttrs holds either a group name or attributes added to a handler.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// Handler is a [slog.Handler] that records every enabled record.
+type Handler struct {
+	*Recorder
+	level slog.Leveler
+	goas  []groupOrAttrs
+}
+
+// NewHandler creates a [Handler] recording records at or above level,
+// all records if level is nil.
+func NewHandler(level slog.Leveler) *Handler {
+	return &Handler{Recorder: new(Recorder), level: level}
+}
+
+// Enabled implements [slog.Handler] interface.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.level == nil || level >= h.level.Level()
+}
+
+// Handle records r with resolved attributes, implements [slog.Handler] interface.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	attrs = resolveAttrs(attrs)
+
+	// wrap record attributes into handler groups from the innermost one
+	for _, goa := range slices.Backward(h.goas) {
+		if goa.group == "" {
+			attrs = append(resolveAttrs(goa.attrs), attrs...)
+		} else if len(attrs) > 0 {
+			attrs = []slog.Attr{{Key: goa.group, Value: slog.GroupValue(attrs...)}}
+		}
+	}
+
+	h.add(Record{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: attrs, PC: r.PC})
+	return nil
+}
+
+// WithAttrs implements [slog.Handler] interface.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+// WithGroup implements [slog.Handler] interface.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+// with returns a copy of h sharing the recorder with goa appended.
+func (h *Handler) with(goa groupOrAttrs) *Handler {
+	h2 := *h
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
+
+// resolveAttrs resolves values and applies the [slog.Handler] rules:
+// empty attributes and empty groups are dropped, groups without a key are inlined.
+func resolveAttrs(attrs []slog.Attr) []slog.Attr {
+	resolved := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+		if a.Value.Kind() != slog.KindGroup {
+			resolved = append(resolved, a)
+			continue
+		}
+		group := resolveAttrs(a.Value.Group())
+		switch {
+		case len(group) == 0:
+		case a.Key == "":
+			resolved = append(resolved, group...)
+		default:
+			resolved = append(resolved, slog.Attr{Key: a.Key, Value: slog.GroupValue(group...)})
+		}
+	}
+	return resolved
+}
@@ -0,0 +1,104 @@
+package logtest_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"testing/slogtest"
+
+	"github.com/voler88/conslog/pkg/logtest"
+)
+
+// TestHandlerConformance runs the standard library handler test suite.
+func TestHandlerConformance(t *testing.T) {
+	var h *logtest.Handler
+	slogtest.Run(t,
+		func(*testing.T) slog.Handler {
+			h = logtest.NewHandler(nil)
+			return h
+		},
+		func(*testing.T) map[string]any {
+			records := h.Records()
+			if len(records) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(records))
+			}
+			r := records[0]
+			m := r.Map()
+			if !r.Time.IsZero() {
+				m[slog.TimeKey] = r.Time
+			}
+			m[slog.LevelKey] = r.Level
+			m[slog.MessageKey] = r.Message
+			return m
+		},
+	)
+}
+
+// TestHandlerGroups verifies attribute order and group nesting of records.
+func TestHandlerGroups(t *testing.T) {
+	h := logtest.NewHandler(slog.LevelInfo)
+	l := slog.New(h).With("app", "api").WithGroup("req").With("method", "GET")
+
+	l.Debug("ignored")
+	l.Info("done", "status", 200, slog.Group("user", "id", 42))
+
+	records := h.Records()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	r := records[0]
+	if len(r.Attrs) != 2 || r.Attrs[0].Key != "app" || r.Attrs[1].Key != "req" {
+		t.Fatalf("expected app and req attributes, got %v", r.Attrs)
+	}
+	for path, want := range map[string]any{"app": "api", "req.method": "GET", "req.status": 200, "req.user.id": 42} {
+		v, ok := r.Value(path)
+		if !ok || !v.Equal(slog.AnyValue(want)) {
+			t.Errorf("expected %s=%v, got %v (found %t)", path, want, v, ok)
+		}
+	}
+	if _, ok := r.Value("req.method.x"); ok {
+		t.Error("expected no value below a non-group attribute")
+	}
+}
+
+// TestMatchers verifies record queries.
+func TestMatchers(t *testing.T) {
+	h := logtest.NewHandler(nil)
+	l := slog.New(h)
+	errBoom := errors.New("boom")
+
+	l.Info("user created", "id", 1)
+	l.Warn("slow request", "duration_ms", 1200)
+	l.Error("user delete failed", "id", 2, "err", errBoom)
+
+	tt := []struct {
+		name     string
+		matchers []logtest.Matcher
+		want     int
+	}{
+		{"All", nil, 3},
+		{"Level", []logtest.Matcher{logtest.Level(slog.LevelWarn)}, 1},
+		{"MinLevel", []logtest.Matcher{logtest.MinLevel(slog.LevelWarn)}, 2},
+		{"Message", []logtest.Matcher{logtest.Message("user created")}, 1},
+		{"MessageContains", []logtest.Matcher{logtest.MessageContains("user")}, 2},
+		{"HasAttr", []logtest.Matcher{logtest.HasAttr("id")}, 2},
+		{"AttrInt", []logtest.Matcher{logtest.Attr("id", int64(2))}, 1},
+		{"AttrError", []logtest.Matcher{logtest.Attr("err", errBoom)}, 1},
+		{"Combined", []logtest.Matcher{logtest.MessageContains("user"), logtest.Attr("id", 3)}, 0},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := h.Count(tc.matchers...); got != tc.want {
+				t.Errorf("expected %d records, got %d", tc.want, got)
+			}
+			if h.Has(tc.matchers...) != (tc.want > 0) {
+				t.Errorf("expected Has to be %t", tc.want > 0)
+			}
+		})
+	}
+
+	h.Reset()
+	if len(h.Records()) != 0 {
+		t.Error("expected no records after reset")
+	}
+}
@@ -0,0 +1,22 @@
+package logtest
+
+import (
+	"log/slog"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// Logger is a [logging.Logger] recording its records, and those of loggers
+// derived from it with With and WithGroup, for querying.
+type Logger struct {
+	logging.Logger
+	*Recorder
+}
+
+// NewLogger creates a recording [Logger] at debug level.
+func NewLogger() *Logger {
+	lvl := new(slog.LevelVar)
+	lvl.Set(slog.LevelDebug)
+	h := NewHandler(lvl)
+	return &Logger{logging.NewFromHandler(h, lvl), h.Recorder}
+}
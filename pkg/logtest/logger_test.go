@@ -0,0 +1,26 @@
+package logtest_test
+
+import (
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+	"github.com/voler88/conslog/pkg/logtest"
+)
+
+// TestLogger verifies that derived loggers share records and level control.
+func TestLogger(t *testing.T) {
+	l := logtest.NewLogger()
+	var _ logging.Logger = l
+
+	l.Debug("start")
+	l.WithGroup("db").With("table", "users").Info("migrated", "rows", 3)
+	l.SetLevel(logging.LevelWarn)
+	l.Info("hidden")
+
+	if got := l.Count(); got != 2 {
+		t.Fatalf("expected 2 records, got %d", got)
+	}
+	if !l.Has(logtest.Message("migrated"), logtest.Attr("db.table", "users"), logtest.Attr("db.rows", 3)) {
+		t.Errorf("expected grouped record, got %+v", l.Records())
+	}
+}
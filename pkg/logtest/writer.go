@@ -0,0 +1,49 @@
+package logtest
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// tbWriter writes log output through [testing.TB.Log].
+type tbWriter struct {
+	tb   testing.TB
+	mu   sync.Mutex
+	done bool // set on test cleanup, Log panics after the test completed
+}
+
+// NewWriter returns an [io.Writer] that logs each write through tb.Log,
+// so output is shown with the test and only for failed or verbose tests.
+// Writes after the test completed, e.g. from leaked goroutines, are dropped.
+func NewWriter(tb testing.TB) io.Writer {
+	w := &tbWriter{tb: tb}
+	tb.Cleanup(func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.done = true
+	})
+	return w
+}
+
+// Write implements [io.Writer].
+func (w *tbWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.tb.Helper()
+		w.tb.Log(strings.TrimSuffix(string(p), "\n"))
+	}
+	return len(p), nil
+}
+
+// NewTBLogger creates a [logging.Logger] at debug level writing through tb.Log
+// with the given handler type.
+func NewTBLogger(tb testing.TB, handler logging.HandlerType, options ...logging.Option) logging.Logger {
+	l := logging.NewLogger(NewWriter(tb), handler, options...)
+	l.SetLevel(logging.LevelDebug)
+	return l
+}
@@ -0,0 +1,53 @@
+package logtest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog/pkg/logging"
+	"github.com/voler88/conslog/pkg/logtest"
+)
+
+// fakeTB captures Log and Errorf calls and runs cleanups on demand.
+type fakeTB struct {
+	testing.TB
+	logs     []string
+	errors   []string
+	cleanups []func()
+}
+
+func (tb *fakeTB) Helper()           {}
+func (tb *fakeTB) Cleanup(fn func()) { tb.cleanups = append(tb.cleanups, fn) }
+func (tb *fakeTB) Log(args ...any)   { tb.logs = append(tb.logs, fmt.Sprint(args...)) }
+func (tb *fakeTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+// TestWriter verifies routing through Log and dropping writes after cleanup.
+func TestWriter(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	w := logtest.NewWriter(tb)
+
+	_, _ = w.Write([]byte("first\n"))
+	for _, fn := range tb.cleanups {
+		fn()
+	}
+	if n, err := w.Write([]byte("late\n")); n != 5 || err != nil {
+		t.Errorf("expected dropped write to succeed, got %d, %v", n, err)
+	}
+	if len(tb.logs) != 1 || tb.logs[0] != "first" {
+		t.Errorf("expected single trimmed line, got %q", tb.logs)
+	}
+}
+
+// TestNewTBLogger verifies that debug records reach the test log.
+func TestNewTBLogger(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	l := logtest.NewTBLogger(tb, logging.Text)
+	l.Debug("connecting", "addr", "localhost:5432")
+
+	if len(tb.logs) != 1 || !strings.Contains(tb.logs[0], `msg=connecting addr=localhost:5432`) {
+		t.Errorf("expected debug record in test log, got %q", tb.logs)
+	}
+}
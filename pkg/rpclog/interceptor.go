@@ -0,0 +1,259 @@
+/*
+Package rpclog provides gRPC-style logging interceptor logic without depending
+on grpc-go. Interceptors are built around plain function signatures, so they
+can be wired into grpc-go (or any RPC framework) by thin adapters, while the
+core conslog package stays free of external dependencies. Calls are logged
+through [logging.Logger].
+
+	li := rpclog.New(logger,
+		rpclog.WithCodeFunc(func(err error) string {
+			return status.Code(err).String()
+		}),
+		rpclog.WithPeerFunc(func(ctx context.Context) string {
+			if p, ok := peer.FromContext(ctx); ok {
+				return p.Addr.String()
+			}
+			return ""
+		}),
+	)
+
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(func(
+			ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+		) (any, error) {
+			return li.Unary(ctx, info.FullMethod, req, handler)
+		}),
+		grpc.StreamInterceptor(func(
+			srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+		) error {
+			return li.Stream(ss.Context(), info.FullMethod, func(context.Context) error {
+				return handler(srv, ss)
+			})
+		}),
+	)
+
+	conn, err := grpc.NewClient(target,
+		grpc.WithUnaryInterceptor(func(
+			ctx context.Context, method string, req, reply any,
+			cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+		) error {
+			return li.Client(ctx, method, func(ctx context.Context) error {
+				return invoker(ctx, method, req, reply, cc, opts...)
+			})
+		}),
+	)
+
+Without [WithCodeFunc] status codes are detected from errors implementing
+[StatusCoder] and from context errors, grpc-go status errors are mapped with
+status.Code as above.
+*/
+package rpclog
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"reflect"
+	"strings"
+
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// Status code names matching the gRPC specification.
+const (
+	CodeOK               = "OK"
+	CodeCanceled         = "Canceled"
+	CodeUnknown          = "Unknown"
+	CodeDeadlineExceeded = "DeadlineExceeded"
+)
+
+// StatusCoder is implemented by errors carrying the status code name of
+// a failed RPC, e.g. "NotFound".
+type StatusCoder interface {
+	StatusCode() string
+}
+
+// Interceptor logs RPCs through a [logging.Logger].
+type Interceptor struct {
+	logger    logging.Logger
+	codeFunc  func(error) string
+	peerFunc  func(context.Context) string
+	levelFunc func(code string) logging.Level
+}
+
+// Option configures an [Interceptor].
+type Option func(*Interceptor)
+
+// WithCodeFunc sets the function that maps an RPC error to its status
+// code name, e.g. status.Code(err).String() with grpc-go.
+func WithCodeFunc(fn func(error) string) Option {
+	return func(i *Interceptor) {
+		i.codeFunc = fn
+	}
+}
+
+// WithPeerFunc sets the function that returns the peer address of a call,
+// e.g. from peer.FromContext with grpc-go. The peer is omitted by default.
+func WithPeerFunc(fn func(context.Context) string) Option {
+	return func(i *Interceptor) {
+		i.peerFunc = fn
+	}
+}
+
+// WithLevelFunc sets the function that maps a status code name to the log level.
+func WithLevelFunc(fn func(code string) logging.Level) Option {
+	return func(i *Interceptor) {
+		i.levelFunc = fn
+	}
+}
+
+// New returns an [Interceptor] logging through l.
+func New(l logging.Logger, options ...Option) *Interceptor {
+	i := &Interceptor{
+		logger:    l,
+		codeFunc:  Code,
+		peerFunc:  func(context.Context) string { return "" },
+		levelFunc: DefaultLevel,
+	}
+	for _, opt := range options {
+		opt(i)
+	}
+	return i
+}
+
+// Unary logs a server side unary call of method handled by handler.
+// The handler context carries a child logger with call attributes,
+// available with [logging.FromContext].
+func (i *Interceptor) Unary(
+	ctx context.Context,
+	method string,
+	req any,
+	handler func(ctx context.Context, req any) (any, error),
+) (any, error) {
+	var resp any
+	err := i.call(ctx, method, "unary", func(ctx context.Context) error {
+		var err error
+		resp, err = handler(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+// Stream logs a server side streaming call of method handled by handler.
+// The handler context carries a child logger with call attributes.
+func (i *Interceptor) Stream(ctx context.Context, method string, handler func(ctx context.Context) error) error {
+	return i.call(ctx, method, "stream", handler)
+}
+
+// Client logs a client side call of method performed by invoke.
+func (i *Interceptor) Client(ctx context.Context, method string, invoke func(ctx context.Context) error) error {
+	return i.call(ctx, method, "client", invoke)
+}
+
+// call runs fn and logs the finished call with its duration, code and peer.
+func (i *Interceptor) call(ctx context.Context, method, kind string, fn func(context.Context) error) error {
+	service, name := SplitMethod(method)
+	args := []any{"service", service, "method", name, "kind", kind}
+	if p := i.peerFunc(ctx); p != "" {
+		args = append(args, "peer", p)
+	}
+	l := i.logger.With(args...)
+
+	clock := logging.ClockOf(i.logger)
+	start := clock.Now()
+	err := fn(logging.NewContext(ctx, l))
+	code := i.codeFunc(err)
+
+	result := []any{"code", code, "duration", clock.Now().Sub(start)}
+	if err != nil {
+		result = append(result, "error", err)
+	}
+
+	msg := "rpc finished"
+	if kind == "client" {
+		msg = "rpc call finished"
+	}
+	logging.LogAt(l, i.levelFunc(code), msg, result...)
+	return err
+}
+
+// SplitMethod splits a full method name "/package.Service/Method"
+// into its service and method parts.
+func SplitMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndexByte(fullMethod, '/'); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
+
+// Code returns the status code name of err: "OK" for nil, the code of the
+// first error in the chain implementing [StatusCoder], "Canceled" or
+// "DeadlineExceeded" for context errors and "Unknown" otherwise.
+func Code(err error) string {
+	if err == nil {
+		return CodeOK
+	}
+	for e := range errorChain(err) {
+		if c, ok := e.(StatusCoder); ok && !isNilPointer(e) {
+			if code := c.StatusCode(); code != "" {
+				return code
+			}
+		}
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeDeadlineExceeded
+	}
+	return CodeUnknown
+}
+
+// errorChain yields err and all errors it wraps, depth first.
+func errorChain(err error) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		var walk func(error) bool
+		walk = func(e error) bool {
+			if e == nil {
+				return true
+			}
+			if !yield(e) {
+				return false
+			}
+			switch u := e.(type) {
+			case interface{ Unwrap() error }:
+				return walk(u.Unwrap())
+			case interface{ Unwrap() []error }:
+				for _, inner := range u.Unwrap() {
+					if !walk(inner) {
+						return false
+					}
+				}
+			}
+			return true
+		}
+		walk(err)
+	}
+}
+
+// isNilPointer reports whether err holds a nil pointer, so its methods
+// are not called.
+func isNilPointer(err error) bool {
+	v := reflect.ValueOf(err)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// DefaultLevel maps status code names to log levels like common gRPC logging
+// middleware: OK at Info, client caused codes at Warn and server failures at Error.
+func DefaultLevel(code string) logging.Level {
+	switch code {
+	case CodeOK:
+		return logging.LevelInfo
+	case CodeCanceled, "InvalidArgument", "NotFound", "AlreadyExists", "PermissionDenied",
+		"Unauthenticated", "ResourceExhausted", "FailedPrecondition", "Aborted", "OutOfRange":
+		return logging.LevelWarn
+	default:
+		return logging.LevelError
+	}
+}
@@ -0,0 +1,187 @@
+package rpclog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logging"
+	"github.com/voler88/conslog/pkg/logtest"
+	"github.com/voler88/conslog/pkg/rpclog"
+)
+
+// statusError is an error carrying a status code name.
+type statusError struct{ code string }
+
+func (e *statusError) Error() string      { return "rpc error: " + e.code }
+func (e *statusError) StatusCode() string { return e.code }
+
+// rpcLine holds fields of a JSON RPC log record.
+type rpcLine struct {
+	Level    string `json:"level"`
+	Msg      string `json:"msg"`
+	Service  string `json:"service"`
+	Method   string `json:"method"`
+	Kind     string `json:"kind"`
+	Peer     string `json:"peer"`
+	Code     string `json:"code"`
+	Duration int64  `json:"duration"`
+	Error    any    `json:"error"`
+}
+
+// parseLines decodes JSON log records.
+func parseLines(t *testing.T, buf *bytes.Buffer) []rpcLine {
+	t.Helper()
+	var lines []rpcLine
+	for line := range strings.Lines(buf.String()) {
+		var entry rpcLine
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("failed to parse log line: %v: %s", err, line)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+// TestCode verifies status code detection without grpc-go.
+func TestCode(t *testing.T) {
+	tt := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"Nil", nil, "OK"},
+		{"Plain", errors.New("boom"), "Unknown"},
+		{"Canceled", context.Canceled, "Canceled"},
+		{"Deadline", fmt.Errorf("wrapped: %w", context.DeadlineExceeded), "DeadlineExceeded"},
+		{"StatusCoder", &statusError{"NotFound"}, "NotFound"},
+		{"WrappedStatusCoder", fmt.Errorf("call: %w", &statusError{"NotFound"}), "NotFound"},
+		{"EmptyCode", &statusError{""}, "Unknown"},
+		{"NilPointer", fmt.Errorf("call: %w", (*statusError)(nil)), "Unknown"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := rpclog.Code(tc.err); got != tc.want {
+				t.Errorf("Code(%v) = %q, want %q", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestSplitMethod verifies splitting of full method names.
+func TestSplitMethod(t *testing.T) {
+	service, method := rpclog.SplitMethod("/pkg.v1.Users/GetUser")
+	if service != "pkg.v1.Users" || method != "GetUser" {
+		t.Errorf("unexpected split: %q %q", service, method)
+	}
+	service, method = rpclog.SplitMethod("Ping")
+	if service != "unknown" || method != "Ping" {
+		t.Errorf("unexpected split without service: %q %q", service, method)
+	}
+}
+
+// TestUnary verifies logging of unary server calls and the context logger.
+func TestUnary(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+	li := rpclog.New(l, rpclog.WithPeerFunc(func(context.Context) string { return "10.0.0.1:5000" }))
+
+	resp, err := li.Unary(context.Background(), "/pkg.Users/Get", "req",
+		func(ctx context.Context, req any) (any, error) {
+			logging.FromContext(ctx).Info("handling")
+			return req.(string) + "-resp", nil
+		})
+	if err != nil || resp != "req-resp" {
+		t.Fatalf("unexpected result: %v %v", resp, err)
+	}
+
+	lines := parseLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), buf.String())
+	}
+	if lines[0].Msg != "handling" || lines[0].Method != "Get" {
+		t.Errorf("expected handler record with call attributes, got %+v", lines[0])
+	}
+	got := lines[1]
+	if got.Level != "INFO" || got.Service != "pkg.Users" || got.Method != "Get" || got.Kind != "unary" ||
+		got.Peer != "10.0.0.1:5000" || got.Code != "OK" || got.Duration <= 0 || got.Error != nil {
+		t.Errorf("unexpected call record: %+v", got)
+	}
+}
+
+// TestClock verifies call durations measured with the logger clock.
+func TestClock(t *testing.T) {
+	clock := logtest.NewClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	var buf bytes.Buffer
+	li := rpclog.New(logging.NewLogger(&buf, logging.JSON, logging.WithClock(clock)))
+
+	_, _ = li.Unary(context.Background(), "/pkg.Users/Get", "req",
+		func(context.Context, any) (any, error) {
+			clock.Advance(1500 * time.Millisecond)
+			return nil, nil
+		})
+
+	lines := parseLines(t, &buf)
+	if len(lines) != 1 || lines[0].Duration != int64(1500*time.Millisecond) {
+		t.Errorf("expected duration of 1.5s on the fake clock, got: %s", buf.String())
+	}
+}
+
+// TestStreamAndClientLevels verifies levels by status code for stream and client calls.
+func TestStreamAndClientLevels(t *testing.T) {
+	tt := []struct {
+		name      string
+		err       error
+		client    bool
+		options   []rpclog.Option
+		wantLevel string
+		wantCode  string
+	}{
+		{"StreamNotFound", &statusError{"NotFound"}, false, nil, "WARN", "NotFound"},
+		{"StreamUnknown", errors.New("boom"), false, nil, "ERROR", "Unknown"},
+		{"ClientCanceled", context.Canceled, true, nil, "WARN", "Canceled"},
+		{
+			name:   "CustomCodeAndLevel",
+			err:    errors.New("boom"),
+			client: true,
+			options: []rpclog.Option{
+				rpclog.WithCodeFunc(func(error) string { return "Internal" }),
+				rpclog.WithLevelFunc(func(string) logging.Level { return logging.LevelDebug }),
+			},
+			wantLevel: "DEBUG",
+			wantCode:  "Internal",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := logging.NewLogger(&buf, logging.JSON)
+			l.SetLevel(logging.LevelDebug)
+			li := rpclog.New(l, tc.options...)
+
+			fn := func(context.Context) error { return tc.err }
+			var err error
+			if tc.client {
+				err = li.Client(context.Background(), "/pkg.Users/List", fn)
+			} else {
+				err = li.Stream(context.Background(), "/pkg.Users/List", fn)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected handler error to be returned, got %v", err)
+			}
+
+			lines := parseLines(t, &buf)
+			if len(lines) != 1 {
+				t.Fatalf("expected 1 log line, got %d", len(lines))
+			}
+			if lines[0].Level != tc.wantLevel || lines[0].Code != tc.wantCode || lines[0].Error == nil {
+				t.Errorf("unexpected record: %+v", lines[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,592 @@
+/*
+Package sqllog wraps [database/sql/driver] drivers to log queries through
+[logging.Logger]. Each statement is logged with its SQL text, arguments,
+duration, row count and error. Queries slower than a threshold are logged
+at Warn level and [conslog.ConsoleHandler] renders the SQL as an indented block.
+
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return err
+	}
+	db := sql.OpenDB(sqllog.NewConnector(connector, logger,
+		sqllog.WithSlowThreshold(200*time.Millisecond),
+	))
+
+Drivers without a [driver.Connector] can be wrapped with [Wrap] and opened
+with [sql.OpenDB] and [NewDriverConnector].
+*/
+package sqllog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/voler88/conslog"
+	"github.com/voler88/conslog/pkg/logging"
+)
+
+// Redacted replaces argument values in log output.
+const Redacted = logging.Redacted
+
+// sensitiveArgs lists named argument names whose values are always redacted.
+var sensitiveArgs = []string{"password", "secret", "token", "api_key", "access_token"}
+
+// Option configures query logging.
+type Option func(*config)
+
+// config holds query logging settings.
+type config struct {
+	level         logging.Level               // level of successful statements
+	slowThreshold time.Duration               // duration escalating to Warn, 0 disables it
+	redactArg     func(driver.NamedValue) any // returns the logged form of an argument
+	logArgs       bool                        // log statement arguments
+	skip          func(query string) bool     // statements excluded from logging
+}
+
+// newConfig applies options to a default configuration.
+func newConfig(options []Option) *config {
+	cfg := &config{
+		level:     logging.LevelDebug,
+		redactArg: defaultRedactArg,
+		logArgs:   true,
+		skip:      func(string) bool { return false },
+	}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithLevel sets the level of successful statements, Debug by default.
+func WithLevel(level logging.Level) Option {
+	return func(c *config) {
+		c.level = level
+	}
+}
+
+// WithSlowThreshold logs statements taking at least d at Warn level
+// with a "slow" attribute.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(c *config) {
+		c.slowThreshold = d
+	}
+}
+
+// WithArgRedactor sets the function returning the logged form of an argument,
+// e.g. [Redacted] for sensitive values. By default named arguments with
+// credential names are redacted and byte slices are logged by length.
+func WithArgRedactor(fn func(driver.NamedValue) any) Option {
+	return func(c *config) {
+		c.redactArg = fn
+	}
+}
+
+// WithoutArgs omits statement arguments from log output.
+func WithoutArgs() Option {
+	return func(c *config) {
+		c.logArgs = false
+	}
+}
+
+// WithSkip excludes statements for which fn returns true from logging.
+func WithSkip(fn func(query string) bool) Option {
+	return func(c *config) {
+		c.skip = fn
+	}
+}
+
+// defaultRedactArg redacts credentials passed as named arguments
+// and replaces byte slices by their length.
+func defaultRedactArg(arg driver.NamedValue) any {
+	if slices.Contains(sensitiveArgs, strings.ToLower(arg.Name)) {
+		return Redacted
+	}
+	if b, ok := arg.Value.([]byte); ok {
+		return "[" + strconv.Itoa(len(b)) + " bytes]"
+	}
+	return arg.Value
+}
+
+// logger logs statements according to its configuration.
+type logger struct {
+	l     logging.Logger
+	cfg   *config
+	clock conslog.Clock // measures durations, see [logging.ClockOf]
+}
+
+// statement describes a finished statement for logging.
+type statement struct {
+	msg          string
+	query        string
+	args         []driver.NamedValue
+	start        time.Time
+	rows         int64
+	rowsAffected int64
+	err          error
+}
+
+// log writes a record for a finished statement. Driver fallbacks
+// signaled with [driver.ErrSkip] are not logged.
+func (lg *logger) log(s statement) {
+	if errors.Is(s.err, driver.ErrSkip) || (s.query != "" && lg.cfg.skip(s.query)) {
+		return
+	}
+
+	duration := lg.clock.Now().Sub(s.start)
+	args := []any{"duration", duration}
+	if s.query != "" {
+		args = append(args, slog.Any("query", conslog.Block(s.query)))
+	}
+	if lg.cfg.logArgs && len(s.args) > 0 {
+		values := make([]any, len(s.args))
+		for i, a := range s.args {
+			values[i] = lg.cfg.redactArg(a)
+		}
+		args = append(args, "args", values)
+	}
+	if s.rows >= 0 {
+		args = append(args, "rows", s.rows)
+	}
+	if s.rowsAffected >= 0 {
+		args = append(args, "rows_affected", s.rowsAffected)
+	}
+
+	level := lg.cfg.level
+	if lg.cfg.slowThreshold > 0 && duration >= lg.cfg.slowThreshold {
+		args = append(args, "slow", true)
+		level = max(level, logging.LevelWarn)
+	}
+	if s.err != nil && !errors.Is(s.err, io.EOF) {
+		args = append(args, "error", s.err)
+		level = logging.LevelError
+	}
+
+	logging.LogAt(lg.l, level, s.msg, args...)
+}
+
+// Wrap returns a [driver.Driver] that logs statements of connections opened by d.
+func Wrap(d driver.Driver, l logging.Logger, options ...Option) driver.Driver {
+	return &wrappedDriver{d, &logger{l, newConfig(options), logging.ClockOf(l)}}
+}
+
+// wrappedDriver logs statements of connections opened by the underlying driver.
+type wrappedDriver struct {
+	driver.Driver
+	lg *logger
+}
+
+// Open implements [driver.Driver].
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{c, d.lg}, nil
+}
+
+// NewConnector returns a [driver.Connector] that logs statements of
+// connections opened by c, for use with [sql.OpenDB].
+func NewConnector(c driver.Connector, l logging.Logger, options ...Option) driver.Connector {
+	return &connector{c, &wrappedDriver{c.Driver(), &logger{l, newConfig(options), logging.ClockOf(l)}}}
+}
+
+// NewDriverConnector returns a [driver.Connector] opening connections with
+// name from d, which is typically returned by [Wrap].
+func NewDriverConnector(d driver.Driver, name string) driver.Connector {
+	return dsnConnector{d, name}
+}
+
+// connector wraps connections of the underlying connector.
+type connector struct {
+	c driver.Connector
+	d *wrappedDriver
+}
+
+// Connect implements [driver.Connector].
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn2, err := c.c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{conn2, c.d.lg}, nil
+}
+
+// Driver implements [driver.Connector].
+func (c *connector) Driver() driver.Driver {
+	return c.d
+}
+
+// dsnConnector opens connections from a driver and data source name.
+type dsnConnector struct {
+	d    driver.Driver
+	name string
+}
+
+// Connect implements [driver.Connector].
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.d.Open(c.name)
+}
+
+// Driver implements [driver.Connector].
+func (c dsnConnector) Driver() driver.Driver {
+	return c.d
+}
+
+// conn logs statements executed on the underlying connection. Optional
+// interfaces not implemented by it return [driver.ErrSkip] or a neutral
+// result, so [database/sql] falls back as it would without the wrapper.
+type conn struct {
+	c  driver.Conn
+	lg *logger
+}
+
+// Prepare implements [driver.Conn].
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext implements [driver.ConnPrepareContext].
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := c.lg.clock.Now()
+	var (
+		s   driver.Stmt
+		err error
+	)
+	if pc, ok := c.c.(driver.ConnPrepareContext); ok {
+		s, err = pc.PrepareContext(ctx, query)
+	} else {
+		s, err = c.c.Prepare(query)
+	}
+	if err != nil {
+		c.lg.log(statement{msg: "sql prepare", query: query, start: start, rows: -1, rowsAffected: -1, err: err})
+		return nil, err
+	}
+	return &stmt{s, query, c, c.lg}, nil
+}
+
+// Close implements [driver.Conn].
+func (c *conn) Close() error {
+	return c.c.Close()
+}
+
+// Begin implements [driver.Conn].
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements [driver.ConnBeginTx].
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := c.lg.clock.Now()
+	var (
+		t   driver.Tx
+		err error
+	)
+	if bc, ok := c.c.(driver.ConnBeginTx); ok {
+		t, err = bc.BeginTx(ctx, opts)
+	} else if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+		err = errors.New("sqllog: driver does not support non-default transaction options")
+	} else {
+		t, err = c.c.Begin()
+	}
+	c.lg.log(statement{msg: "sql begin", start: start, rows: -1, rowsAffected: -1, err: err})
+	if err != nil {
+		return nil, err
+	}
+	return &tx{t, c.lg}, nil
+}
+
+// ExecContext implements [driver.ExecerContext].
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.c.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := c.lg.clock.Now()
+	res, err := ec.ExecContext(ctx, query, args)
+	c.lg.log(statement{
+		msg: "sql exec", query: query, args: args, start: start,
+		rows: -1, rowsAffected: rowsAffected(res, err), err: err,
+	})
+	return res, err
+}
+
+// QueryContext implements [driver.QueryerContext].
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.c.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := c.lg.clock.Now()
+	r, err := qc.QueryContext(ctx, query, args)
+	if err != nil {
+		c.lg.log(statement{msg: "sql query", query: query, args: args, start: start, rows: -1, rowsAffected: -1, err: err})
+		return nil, err
+	}
+	return &rows{r, statement{msg: "sql query", query: query, args: args, start: start, rowsAffected: -1}, c.lg}, nil
+}
+
+// Ping implements [driver.Pinger].
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.c.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements [driver.SessionResetter].
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.c.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid implements [driver.Validator].
+func (c *conn) IsValid() bool {
+	if v, ok := c.c.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue implements [driver.NamedValueChecker].
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.c.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// tx logs commits and rollbacks of the underlying transaction.
+type tx struct {
+	tx driver.Tx
+	lg *logger
+}
+
+// Commit implements [driver.Tx].
+func (t *tx) Commit() error {
+	start := t.lg.clock.Now()
+	err := t.tx.Commit()
+	t.lg.log(statement{msg: "sql commit", start: start, rows: -1, rowsAffected: -1, err: err})
+	return err
+}
+
+// Rollback implements [driver.Tx].
+func (t *tx) Rollback() error {
+	start := t.lg.clock.Now()
+	err := t.tx.Rollback()
+	t.lg.log(statement{msg: "sql rollback", start: start, rows: -1, rowsAffected: -1, err: err})
+	return err
+}
+
+// stmt logs executions of the underlying prepared statement.
+type stmt struct {
+	s     driver.Stmt
+	query string
+	conn  *conn // checks arguments the statement does not check itself
+	lg    *logger
+}
+
+// Close implements [driver.Stmt].
+func (s *stmt) Close() error {
+	return s.s.Close()
+}
+
+// NumInput implements [driver.Stmt].
+func (s *stmt) NumInput() int {
+	return s.s.NumInput()
+}
+
+// Exec implements [driver.Stmt].
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+// Query implements [driver.Stmt].
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// ExecContext implements [driver.StmtExecContext].
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := s.lg.clock.Now()
+	var (
+		res driver.Result
+		err error
+	)
+	if ec, ok := s.s.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else {
+		res, err = s.s.Exec(values(args))
+	}
+	s.lg.log(statement{
+		msg: "sql exec", query: s.query, args: args, start: start,
+		rows: -1, rowsAffected: rowsAffected(res, err), err: err,
+	})
+	return res, err
+}
+
+// QueryContext implements [driver.StmtQueryContext].
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := s.lg.clock.Now()
+	var (
+		r   driver.Rows
+		err error
+	)
+	if qc, ok := s.s.(driver.StmtQueryContext); ok {
+		r, err = qc.QueryContext(ctx, args)
+	} else {
+		r, err = s.s.Query(values(args))
+	}
+	if err != nil {
+		s.lg.log(statement{msg: "sql query", query: s.query, args: args, start: start, rows: -1, rowsAffected: -1, err: err})
+		return nil, err
+	}
+	return &rows{r, statement{msg: "sql query", query: s.query, args: args, start: start, rowsAffected: -1}, s.lg}, nil
+}
+
+// CheckNamedValue implements [driver.NamedValueChecker]. Without a checker
+// of the statement the one of the connection is used, as [database/sql]
+// does for unwrapped statements.
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.s.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	if nc, ok := s.conn.c.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// ColumnConverter implements [driver.ColumnConverter], statements without
+// one convert arguments with [driver.DefaultParameterConverter].
+func (s *stmt) ColumnConverter(idx int) driver.ValueConverter {
+	if cc, ok := s.s.(driver.ColumnConverter); ok {
+		return cc.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+// rows counts rows read from the underlying result set and logs the
+// query when it is closed.
+type rows struct {
+	driver.Rows
+	st statement
+	lg *logger
+}
+
+// Next implements [driver.Rows].
+func (r *rows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.st.rows++
+	} else if !errors.Is(err, io.EOF) && r.st.err == nil {
+		r.st.err = err
+	}
+	return err
+}
+
+// Close implements [driver.Rows].
+func (r *rows) Close() error {
+	err := r.Rows.Close()
+	if r.st.err == nil {
+		r.st.err = err
+	}
+	r.lg.log(r.st)
+	return err
+}
+
+// HasNextResultSet implements [driver.RowsNextResultSet].
+func (r *rows) HasNextResultSet() bool {
+	if nr, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return nr.HasNextResultSet()
+	}
+	return false
+}
+
+// NextResultSet implements [driver.RowsNextResultSet].
+func (r *rows) NextResultSet() error {
+	if nr, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return nr.NextResultSet()
+	}
+	return io.EOF
+}
+
+// ColumnTypeScanType implements [driver.RowsColumnTypeScanType].
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+	return reflect.TypeFor[any]()
+}
+
+// ColumnTypeDatabaseTypeName implements [driver.RowsColumnTypeDatabaseTypeName].
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+// ColumnTypeLength implements [driver.RowsColumnTypeLength].
+func (r *rows) ColumnTypeLength(index int) (int64, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+// ColumnTypeNullable implements [driver.RowsColumnTypeNullable].
+func (r *rows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+// ColumnTypePrecisionScale implements [driver.RowsColumnTypePrecisionScale].
+func (r *rows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
+// rowsAffected returns the affected row count of res, or -1 if unknown.
+func rowsAffected(res driver.Result, err error) int64 {
+	if err != nil || res == nil {
+		return -1
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// namedValues converts positional values to ordinal named values.
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+// values converts named values to positional values.
+func values(args []driver.NamedValue) []driver.Value {
+	vals := make([]driver.Value, len(args))
+	for i, a := range args {
+		vals[i] = a.Value
+	}
+	return vals
+}
@@ -0,0 +1,381 @@
+package sqllog_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voler88/conslog/pkg/logging"
+	"github.com/voler88/conslog/pkg/logtest"
+	"github.com/voler88/conslog/pkg/sqllog"
+)
+
+// fakeDriver is a minimal legacy driver supporting only prepared statements.
+type fakeDriver struct {
+	delay time.Duration  // delay of every statement
+	clock *logtest.Clock // advanced by delay instead of sleeping if set
+}
+
+// wait delays a statement.
+func (d *fakeDriver) wait() {
+	if d.clock != nil {
+		d.clock.Advance(d.delay)
+		return
+	}
+	time.Sleep(d.delay)
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.Contains(query, "syntax error") {
+		return nil, errors.New("syntax error at position 1")
+	}
+	return &fakeStmt{c.d, query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.d.wait()
+	return driver.RowsAffected(3), nil
+}
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.wait()
+	return &fakeRows{n: 2}, nil
+}
+
+type fakeRows struct{ n int }
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.n == 0 {
+		return io.EOF
+	}
+	dest[0] = int64(r.n)
+	r.n--
+	return nil
+}
+
+// fakeCtxConn adds direct context execution and querying to [fakeConn].
+type fakeCtxConn struct{ fakeConn }
+
+func (c *fakeCtxConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return nil, errors.New("constraint violation")
+}
+func (c *fakeCtxConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{n: 1}, nil
+}
+
+type fakeConnector struct{ d *fakeDriver }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeCtxConn{fakeConn{c.d}}, nil
+}
+func (c fakeConnector) Driver() driver.Driver { return c.d }
+
+// point is an argument type only converted by the checking fakes.
+type point struct{ X, Y int }
+
+// convertPoint converts points to strings and other values by default.
+type convertPoint struct{}
+
+func (convertPoint) ConvertValue(v any) (driver.Value, error) {
+	if p, ok := v.(point); ok {
+		return fmt.Sprintf("(%d,%d)", p.X, p.Y), nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+type fakeCheckerConn struct{ fakeConn }
+
+func (c *fakeCheckerConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if _, ok := nv.Value.(point); !ok {
+		return driver.ErrSkip
+	}
+	var err error
+	nv.Value, err = convertPoint{}.ConvertValue(nv.Value)
+	return err
+}
+
+type fakeConverterConn struct{ fakeConn }
+
+func (c *fakeConverterConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeConverterStmt{fakeStmt{c.d, query}}, nil
+}
+
+type fakeConverterStmt struct{ fakeStmt }
+
+func (s *fakeConverterStmt) ColumnConverter(int) driver.ValueConverter { return convertPoint{} }
+
+type connConnector struct {
+	d    *fakeDriver
+	conn driver.Conn
+}
+
+func (c connConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c connConnector) Driver() driver.Driver                        { return c.d }
+
+// sqlLine holds fields of a JSON statement log record.
+type sqlLine struct {
+	Level        string `json:"level"`
+	Msg          string `json:"msg"`
+	Query        string `json:"query"`
+	Args         []any  `json:"args"`
+	Duration     int64  `json:"duration"`
+	Rows         *int   `json:"rows"`
+	RowsAffected *int   `json:"rows_affected"`
+	Slow         bool   `json:"slow"`
+	Error        any    `json:"error"`
+}
+
+// parseLines decodes JSON log records.
+func parseLines(t *testing.T, buf *bytes.Buffer) []sqlLine {
+	t.Helper()
+	var lines []sqlLine
+	for line := range strings.Lines(buf.String()) {
+		var entry sqlLine
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("failed to parse log line: %v: %s", err, line)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+// openDB returns a database using a wrapped legacy driver and its log output.
+func openDB(t *testing.T, d *fakeDriver, options ...sqllog.Option) (*sql.DB, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+	l.SetLevel(logging.LevelDebug)
+
+	db := sql.OpenDB(sqllog.NewDriverConnector(sqllog.Wrap(d, l, options...), "fake"))
+	t.Cleanup(func() { _ = db.Close() })
+	return db, &buf
+}
+
+// TestQuery verifies query logging with arguments and row counts.
+func TestQuery(t *testing.T) {
+	db, buf := openDB(t, &fakeDriver{})
+
+	rows, err := db.Query("SELECT id FROM users WHERE name = ? AND pass = ?",
+		"bob", sql.Named("password", "hunter2"))
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	var n int
+	for rows.Next() {
+		n++
+	}
+	_ = rows.Close()
+	if n != 2 {
+		t.Fatalf("expected 2 rows, got %d", n)
+	}
+
+	lines := parseLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 log line, got %d: %s", len(lines), buf.String())
+	}
+	got := lines[0]
+	if got.Level != "DEBUG" || got.Msg != "sql query" || !strings.HasPrefix(got.Query, "SELECT id") ||
+		got.Rows == nil || *got.Rows != 2 || got.Duration <= 0 {
+		t.Errorf("unexpected record: %+v", got)
+	}
+	if len(got.Args) != 2 || got.Args[0] != "bob" || got.Args[1] != sqllog.Redacted {
+		t.Errorf("expected redacted named argument, got %v", got.Args)
+	}
+}
+
+// TestExec verifies exec logging, slow statements and argument options.
+func TestExec(t *testing.T) {
+	t.Run("RowsAffected", func(t *testing.T) {
+		db, buf := openDB(t, &fakeDriver{}, sqllog.WithLevel(logging.LevelInfo))
+		if _, err := db.Exec("UPDATE users SET active = ?", []byte("blob")); err != nil {
+			t.Fatalf("exec failed: %v", err)
+		}
+		got := parseLines(t, buf)[0]
+		if got.Level != "INFO" || got.Msg != "sql exec" || got.RowsAffected == nil || *got.RowsAffected != 3 {
+			t.Errorf("unexpected record: %+v", got)
+		}
+		if len(got.Args) != 1 || got.Args[0] != "[4 bytes]" {
+			t.Errorf("expected byte slice argument by length, got %v", got.Args)
+		}
+	})
+
+	t.Run("Slow", func(t *testing.T) {
+		db, buf := openDB(t, &fakeDriver{delay: 5 * time.Millisecond},
+			sqllog.WithSlowThreshold(time.Millisecond), sqllog.WithoutArgs())
+		if _, err := db.Exec("DELETE FROM sessions WHERE id = ?", 1); err != nil {
+			t.Fatalf("exec failed: %v", err)
+		}
+		got := parseLines(t, buf)[0]
+		if got.Level != "WARN" || !got.Slow || got.Args != nil {
+			t.Errorf("expected slow statement at warn level without args, got %+v", got)
+		}
+	})
+
+	t.Run("Clock", func(t *testing.T) {
+		clock := logtest.NewClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+		var buf bytes.Buffer
+		l := logging.NewLogger(&buf, logging.JSON, logging.WithClock(clock))
+		l.SetLevel(logging.LevelDebug)
+		d := &fakeDriver{delay: 2 * time.Second, clock: clock}
+		db := sql.OpenDB(sqllog.NewDriverConnector(sqllog.Wrap(d, l, sqllog.WithSlowThreshold(time.Second)), "fake"))
+		defer db.Close()
+
+		if _, err := db.Exec("DELETE FROM sessions"); err != nil {
+			t.Fatalf("exec failed: %v", err)
+		}
+		got := parseLines(t, &buf)[0]
+		if got.Duration != int64(2*time.Second) || !got.Slow {
+			t.Errorf("expected slow statement of 2s on the fake clock, got %+v", got)
+		}
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		db, buf := openDB(t, &fakeDriver{},
+			sqllog.WithSkip(func(q string) bool { return strings.HasPrefix(q, "SELECT 1") }))
+		if _, err := db.Exec("SELECT 1"); err != nil {
+			t.Fatalf("exec failed: %v", err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("expected skipped statement not to be logged, got: %s", buf.String())
+		}
+	})
+}
+
+// TestErrorsAndTransactions verifies error levels and transaction records.
+func TestErrorsAndTransactions(t *testing.T) {
+	db, buf := openDB(t, &fakeDriver{})
+
+	if _, err := db.Exec("syntax error"); err == nil {
+		t.Fatal("expected prepare error")
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("begin failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+
+	lines := parseLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 log lines, got %d: %s", len(lines), buf.String())
+	}
+	if lines[0].Level != "ERROR" || lines[0].Msg != "sql prepare" || lines[0].Error == nil {
+		t.Errorf("expected prepare error record, got %+v", lines[0])
+	}
+	if lines[1].Msg != "sql begin" || lines[2].Msg != "sql commit" {
+		t.Errorf("expected transaction records, got %q and %q", lines[1].Msg, lines[2].Msg)
+	}
+	if _, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true}); err == nil {
+		t.Error("expected error for unsupported transaction options")
+	}
+}
+
+// TestConnector verifies wrapping a connector with direct context execution.
+func TestConnector(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.JSON)
+	l.SetLevel(logging.LevelDebug)
+	db := sql.OpenDB(sqllog.NewConnector(fakeConnector{&fakeDriver{}}, l))
+	defer db.Close()
+
+	if _, err := db.Exec("INSERT INTO users VALUES (?)", 1); err == nil {
+		t.Fatal("expected exec error")
+	}
+	var id int
+	if err := db.QueryRow("SELECT id FROM users").Scan(&id); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+
+	lines := parseLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), buf.String())
+	}
+	if lines[0].Level != "ERROR" || lines[0].Msg != "sql exec" {
+		t.Errorf("expected exec error record, got %+v", lines[0])
+	}
+	if lines[1].Msg != "sql query" || lines[1].Rows == nil || *lines[1].Rows != 1 {
+		t.Errorf("expected query record with 1 row, got %+v", lines[1])
+	}
+}
+
+// TestArgumentConversion verifies that statements convert arguments with
+// the value checker of the connection or the column converter of the
+// statement, like unwrapped ones.
+func TestArgumentConversion(t *testing.T) {
+	d := &fakeDriver{}
+	tests := []struct {
+		name string
+		conn driver.Conn
+	}{
+		{"ConnChecker", &fakeCheckerConn{fakeConn{d}}},
+		{"ColumnConverter", &fakeConverterConn{fakeConn{d}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := logging.NewLogger(&buf, logging.JSON)
+			l.SetLevel(logging.LevelDebug)
+			db := sql.OpenDB(sqllog.NewConnector(connConnector{d, tt.conn}, l))
+			defer db.Close()
+
+			st, err := db.Prepare("INSERT INTO shapes VALUES (?)")
+			if err != nil {
+				t.Fatalf("prepare failed: %v", err)
+			}
+			defer st.Close()
+			if _, err := st.Exec(point{1, 2}); err != nil {
+				t.Fatalf("exec failed: %v", err)
+			}
+			if !strings.Contains(buf.String(), `"(1,2)"`) {
+				t.Errorf("expected converted argument, got %s", buf.String())
+			}
+		})
+	}
+}
+
+// TestConsoleRendering verifies that SQL is rendered as an indented block.
+func TestConsoleRendering(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.NewLogger(&buf, logging.Console)
+	l.SetLevel(logging.LevelDebug)
+	db := sql.OpenDB(sqllog.NewDriverConnector(sqllog.Wrap(&fakeDriver{}, l), "fake"))
+	defer db.Close()
+
+	if _, err := db.Exec("UPDATE users\nSET active = 1"); err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+	out := regexp.MustCompile(`\x1b\[[0-9;]*m`).ReplaceAllString(buf.String(), "")
+	if !strings.Contains(out, "  query: |\n") ||
+		!strings.Contains(out, "    UPDATE users") || !strings.Contains(out, "    SET active = 1") {
+		t.Errorf("expected SQL block, got:\n%s", out)
+	}
+}
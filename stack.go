@@ -0,0 +1,101 @@
+package conslog
+
+import (
+	"log/slog"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxStackDepth limits the number of frames captured for a record.
+const maxStackDepth = 64
+
+// stackFrame is a single resolved entry of a call stack.
+type stackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// stackTrace is a resolved call stack, marshaled to JSON as an array of frames
+// and rendered natively by [ConsoleHandler].
+type stackTrace []stackFrame
+
+// resolveFrames converts program counters into stack frames.
+func resolveFrames(pcs []uintptr) stackTrace {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	frames := make(stackTrace, 0, len(pcs))
+	iter := runtime.CallersFrames(pcs)
+	for {
+		f, more := iter.Next()
+		frames = append(frames, stackFrame{Function: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// callerStack captures the current goroutine stack starting at the frame
+// with program counter pc, typically [slog.Record.PC]. Handler frames are
+// kept only when pc is not found on the stack, e.g. for records handled
+// asynchronously. Returns nil if pc is zero.
+func callerStack(pc uintptr) stackTrace {
+	if pc == 0 {
+		return nil
+	}
+
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(2, pcs) // skip runtime.Callers and callerStack
+	pcs = pcs[:n]
+	if i := slices.Index(pcs, pc); i >= 0 {
+		pcs = pcs[i:]
+	}
+	return resolveFrames(pcs)
+}
+
+// StackValue returns the call stack of the log call identified by pc,
+// typically [slog.Record.PC], as a value that JSON handlers marshal to
+// an array of {"function", "file", "line"} objects and [ConsoleHandler]
+// renders as an indented trace. It must be called synchronously from
+// [slog.Handler.Handle].
+func StackValue(pc uintptr) slog.Value {
+	return slog.AnyValue(callerStack(pc))
+}
+
+// isStdlibFrame reports whether function belongs to the standard library
+// or runtime, whose import paths have no dot in their first element.
+func isStdlibFrame(function string) bool {
+	if strings.HasPrefix(function, "main.") {
+		return false
+	}
+	pkg := function
+	if i := strings.LastIndexByte(pkg, '/'); i >= 0 {
+		pkg = pkg[:i] // drop the last element, it contains the function name
+	} else if i := strings.IndexByte(pkg, '.'); i >= 0 {
+		pkg = pkg[:i] // single element path, e.g. "runtime.goexit"
+	}
+	first, _, _ := strings.Cut(pkg, "/")
+	return !strings.Contains(first, ".")
+}
+
+// appendStack writes frames as function names followed by their indented
+// source locations, dimming standard library and runtime frames.
+func appendStack(b *strings.Builder, frames stackTrace, indent int) {
+	prefix := getIndent(indent)
+	locPrefix := getIndent(indent + 1)
+	for _, f := range frames {
+		funcColor := ansiLightGray
+		if isStdlibFrame(f.Function) {
+			funcColor = ansiDarkGray
+		}
+		b.WriteString(prefix)
+		b.WriteString(colorize(funcColor, f.Function+"()\n"))
+		b.WriteString(locPrefix)
+		b.WriteString(colorize(ansiDarkGray, f.File+":"+strconv.Itoa(f.Line)+"\n"))
+	}
+}
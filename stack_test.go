@@ -0,0 +1,105 @@
+package conslog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/voler88/conslog"
+)
+
+// TestWithStackTrace verifies that stack traces are captured only at or above the configured level.
+func TestWithStackTrace(t *testing.T) {
+	var buf bytes.Buffer
+	h := conslog.NewConsoleHandler(&buf, nil, conslog.WithStackTrace(slog.LevelError))
+	logger := slog.New(h)
+
+	logger.Warn("below threshold")
+	if strings.Contains(buf.String(), "stack:") {
+		t.Errorf("expected no stack for warn level, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	logger.Error("at threshold", "key", "value")
+	out := buf.String()
+	plain := uncolorize(t, out)
+
+	lines := strings.Split(plain, "\n")
+	if len(lines) < 5 || lines[2] != "  stack:" {
+		t.Fatalf("expected stack after attributes, got:\n%s", plain)
+	}
+	if want := "    github.com/voler88/conslog_test.TestWithStackTrace()"; lines[3] != want {
+		t.Errorf("expected first frame %q, got %q", want, lines[3])
+	}
+	if !strings.Contains(lines[4], "stack_test.go:") {
+		t.Errorf("expected source location of log call, got %q", lines[4])
+	}
+
+	// user frames are highlighted, standard library frames are dimmed
+	if !strings.Contains(out, ansi(lightGray)+"github.com/voler88/conslog_test.TestWithStackTrace()") {
+		t.Errorf("expected user frame in light gray, got %q", out)
+	}
+	if !strings.Contains(out, ansi(darkGray)+"testing.tRunner()") {
+		t.Errorf("expected standard library frame in dark gray, got %q", out)
+	}
+}
+
+// stackAttrHandler adds a stack attribute to every record, like wrapping handlers do.
+type stackAttrHandler struct {
+	slog.Handler
+}
+
+func (h stackAttrHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(slog.Attr{Key: "stack", Value: conslog.StackValue(r.PC)})
+	return h.Handler.Handle(ctx, r)
+}
+
+// TestStackValue verifies the structured JSON and native console forms of [conslog.StackValue].
+func TestStackValue(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		slog.New(stackAttrHandler{slog.NewJSONHandler(&buf, nil)}).Info("test")
+
+		var line struct {
+			Stack []struct {
+				Function string `json:"function"`
+				File     string `json:"file"`
+				Line     int    `json:"line"`
+			} `json:"stack"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+			t.Fatalf("failed to parse log line: %v", err)
+		}
+		if len(line.Stack) == 0 {
+			t.Fatalf("expected stack frames, got: %s", buf.String())
+		}
+		first := line.Stack[0]
+		if !strings.HasSuffix(first.Function, "TestStackValue.func1") ||
+			!strings.HasSuffix(first.File, "stack_test.go") || first.Line == 0 {
+			t.Errorf("unexpected first frame: %+v", first)
+		}
+	})
+
+	t.Run("Console", func(t *testing.T) {
+		var buf bytes.Buffer
+		slog.New(stackAttrHandler{conslog.NewConsoleHandler(&buf, nil)}).Info("test")
+
+		plain := uncolorize(t, buf.String())
+		if !strings.Contains(plain, "  stack:\n    github.com/voler88/conslog_test.TestStackValue.func2()\n") {
+			t.Errorf("expected natively rendered stack, got:\n%s", plain)
+		}
+	})
+
+	t.Run("ZeroPC", func(t *testing.T) {
+		data, err := json.Marshal(conslog.StackValue(0).Any())
+		if err != nil {
+			t.Fatalf("failed to marshal stack: %v", err)
+		}
+		if string(data) != "null" {
+			t.Errorf("expected empty stack for zero pc, got %s", data)
+		}
+	})
+}